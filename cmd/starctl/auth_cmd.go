@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/staroids/starctl/pkg/api"
+	v1 "github.com/staroids/starctl/pkg/api/v1"
+	"github.com/staroids/starctl/pkg/auth"
+	"github.com/staroids/starctl/pkg/config"
+	"github.com/staroids/starctl/pkg/constants"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// credentialStore keeps access tokens encrypted under the config dir. nil when config dir is not available
+var credentialStore *auth.CredentialStore
+
+func AuthCmdUsage() {
+	fmt.Fprintf(os.Stdout, "auth [add|list|remove|whoami]\n\n")
+	fmt.Fprintf(os.Stdout, "add and remove modify the profile selected by -profile flag, or the current profile\n")
+	fmt.Fprintf(os.Stdout, "set %s to encrypt with a passphrase instead of a key file\n", constants.EnvStarctlCredentialPassphrase)
+}
+
+// NewCredentialStore returns credential store under the config dir
+func NewCredentialStore() (*auth.CredentialStore, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+	return auth.NewCredentialStore(dir, os.Getenv(constants.EnvStarctlCredentialPassphrase)), nil
+}
+
+// hasStoredToken returns true if the credential store has access token of the profile
+func hasStoredToken(profile string) bool {
+	if credentialStore == nil {
+		return false
+	}
+	token, _ := credentialStore.Get(profile)
+	return token != ""
+}
+
+// readToken reads access token from terminal without echo, or the first line of stdin when it is not a terminal
+func readToken() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if terminal.IsTerminal(fd) {
+		fmt.Printf("Access token: ")
+		token, err := terminal.ReadPassword(fd)
+		fmt.Println()
+		return strings.TrimSpace(string(token)), err
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("Can't read access token: %v", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// Whoami is the identity of the access token in use
+type Whoami struct {
+	User        *v1.StaroidUser `json:"user,omitempty"`
+	Orgs        []v1.StaroidOrg `json:"orgs"`
+	ApiServer   string          `json:"apiServer"`
+	TokenSource string          `json:"tokenSource"`
+	Profile     string          `json:"profile"`
+	Error       string          `json:"error,omitempty"`
+}
+
+// GetWhoami validates the access token by calling the api and returns the identity.
+// Returned Whoami has api server and token source even when err is not nil, e.g. when no token is found
+func GetWhoami(ctx context.Context, client *api.StaroidClient) (*Whoami, error) {
+	whoami := &Whoami{
+		Orgs:        []v1.StaroidOrg{},
+		ApiServer:   client.Auth.ApiServer(),
+		TokenSource: client.Auth.Source(),
+		Profile:     activeProfileName,
+	}
+	if err := client.Auth.CheckAuth(); err != nil {
+		return whoami, err
+	}
+
+	user, err := client.V1().User().WithContext(ctx).Get()
+	if v1.IsUnauthorized(err) {
+		return whoami, fmt.Errorf("Access token from %s is not valid", whoami.TokenSource)
+	} else if err != nil {
+		return whoami, err
+	}
+	whoami.User = user
+
+	orgs, err := client.V1().Org().WithContext(ctx).GetAll()
+	if err != nil {
+		return whoami, err
+	}
+	whoami.Orgs = *orgs
+	return whoami, nil
+}
+
+func WhoamiCmd(ctx context.Context, args []string) {
+	whoamiCmdFlag := flag.NewFlagSet("whoami", flag.ExitOnError)
+	output := whoamiCmdFlag.String("o", "table", "output format. table or json")
+	whoamiCmdFlag.Parse(args)
+
+	if len(whoamiCmdFlag.Args()) > 0 || (*output != "table" && *output != "json") {
+		AuthCmdUsage()
+		os.Exit(1)
+	}
+
+	// not being logged in is reported in the requested output format
+	whoami, err := GetWhoami(ctx, NewClient(NewAuth()))
+	if *output == "json" {
+		if err != nil {
+			whoami.Error = err.Error()
+		}
+		out, _ := json.MarshalIndent(whoami, "", "  ")
+		fmt.Printf("%s\n", string(out))
+		if err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("User:          %s/%s <%s>\n", whoami.User.Provider, whoami.User.Name, whoami.User.Email)
+	fmt.Printf("API server:    %s\n", whoami.ApiServer)
+	fmt.Printf("Token source:  %s\n", whoami.TokenSource)
+	fmt.Printf("Profile:       %s\n\n", whoami.Profile)
+
+	header := []string{"ORG", "ID"}
+	rows := make([]*[]string, 0)
+	for _, org := range whoami.Orgs {
+		rows = append(rows, &[]string{fmt.Sprintf("%s/%s", org.Provider, org.Name), fmt.Sprintf("%d", org.ID)})
+	}
+	PrintTable(&header, &rows)
+}
+
+func AuthCmd(ctx context.Context, args []string) {
+	if len(args) < 1 {
+		AuthCmdUsage()
+		os.Exit(1)
+	}
+
+	if args[0] == "whoami" {
+		WhoamiCmd(ctx, args[1:])
+		return
+	}
+
+	if len(args) != 1 {
+		AuthCmdUsage()
+		os.Exit(1)
+	}
+
+	if credentialStore == nil {
+		fmt.Printf("Credential store is not available\n")
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "add":
+		token, err := readToken()
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		if token == "" {
+			fmt.Printf("Access token is empty\n")
+			os.Exit(1)
+		}
+		if err := credentialStore.Set(activeProfileName, token); err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Access token saved to profile '%s'.\n", activeProfileName)
+	case "list":
+		profiles, err := credentialStore.Profiles()
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+
+		header := []string{"PROFILE", "TOKEN"}
+		rows := make([]*[]string, 0)
+		for _, name := range profiles {
+			token, _ := credentialStore.Get(name)
+			rows = append(rows, &[]string{name, redactToken(token)})
+		}
+		PrintTable(&header, &rows)
+	case "remove":
+		removed, err := credentialStore.Remove(activeProfileName)
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		if !removed {
+			fmt.Printf("No access token stored for profile '%s'\n", activeProfileName)
+			os.Exit(1)
+		}
+		fmt.Printf("Access token removed from profile '%s'.\n", activeProfileName)
+	default:
+		AuthCmdUsage()
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/staroids/starctl/pkg/cache"
+	"github.com/staroids/starctl/pkg/config"
+	"github.com/staroids/starctl/pkg/constants"
+)
+
+func CacheCmdUsage() {
+	fmt.Fprintf(os.Stdout, "cache [clear]\n")
+}
+
+// NewLookupCache returns cache of org, cluster and namespace alias lookups under the config dir
+func NewLookupCache() (*cache.Cache, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+	return cache.New(filepath.Join(dir, "cache"), constants.LookupCacheTTLSec*time.Second), nil
+}
+
+func CacheCmd(args []string) {
+	if len(args) < 1 {
+		CacheCmdUsage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "clear":
+		c, err := NewLookupCache()
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		err = c.Clear()
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Cache cleared\n")
+	default:
+		CacheCmdUsage()
+		os.Exit(1)
+	}
+}
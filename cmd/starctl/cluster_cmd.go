@@ -1,67 +1,342 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/briandowns/spinner"
+	"github.com/staroids/starctl/pkg/api"
 	v1 "github.com/staroids/starctl/pkg/api/v1"
+	"github.com/staroids/starctl/pkg/constants"
 )
 
 func ClusterCmdUsage() {
-	fmt.Fprintf(os.Stdout, "cluster [create|list|get|delete] <name> [flags]\n")
+	fmt.Fprintf(os.Stdout, "cluster [flags] [create|list|get|delete] <name>\n")
+}
+
+// PrintCluster prints details of the cluster and its namespaces
+func PrintCluster(cluster *v1.StaroidCluster, org *v1.StaroidOrg, namespaces *[]v1.StaroidNamespace) {
+	fmt.Printf("Name:    %s\n", cluster.Name)
+	fmt.Printf("ID:      %d\n", cluster.ID)
+	fmt.Printf("Org:     %s/%s\n", org.Provider, org.Name)
+	fmt.Printf("Type:    %s\n", cluster.Type)
+	fmt.Printf("SKE:     %s\n", cluster.Ske.ID)
+	fmt.Printf("Cloud:   %s\n", cluster.Ske.Cloud)
+	fmt.Printf("Region:  %s\n", cluster.Ske.Region)
+
+	fmt.Printf("\nNamespaces: %d\n", len(*namespaces))
+	if len(*namespaces) == 0 {
+		return
+	}
+	header := []string{"ALIAS", "NAME", "TYPE", "PHASE"}
+	rows := make([]*[]string, 0)
+	for _, ns := range *namespaces {
+		rows = append(rows, &[]string{ns.Alias, ns.Namespace, ns.Type, string(ns.Phase)})
+	}
+	PrintTable(&header, &rows)
+}
+
+// activeNamespaces returns namespaces that are not deleted or being deleted
+func activeNamespaces(namespaces *[]v1.StaroidNamespace) *[]v1.StaroidNamespace {
+	active := make([]v1.StaroidNamespace, 0)
+	for _, ns := range *namespaces {
+		if ns.Status == v1.NamespaceStatusInactive || ns.Phase == v1.NamespacePhaseRemoved {
+			continue
+		}
+		active = append(active, ns)
+	}
+	return &active
+}
+
+// DeleteNamespaces deletes namespaces and waits until all of them are removed, up to timeout in total.
+// Failure to delete a namespace does not stop deleting the others. The error lists all namespaces failed to delete
+func DeleteNamespaces(ctx context.Context, builder *v1.NamespaceRequestBuilder, namespaces *[]v1.StaroidNamespace, timeout time.Duration) error {
+	failed := make([]string, 0)
+	for _, ns := range *namespaces {
+		if _, err := builder.DeleteById(ns.ID); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", ns.Alias, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("Can't delete %d of %d namespace(s)\n%s", len(failed), len(*namespaces), strings.Join(failed, "\n"))
+	}
+
+	// namespaces are removed in parallel, so they share a single deadline
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for i := range *namespaces {
+		ns := &(*namespaces)[i]
+		last, err := waitNamespace(waitCtx, builder, ns, v1.PhaseIn(v1.NamespacePhaseRemoved), timeout)
+		if err == context.DeadlineExceeded && ctx.Err() == nil {
+			return &WaitTimeoutError{Alias: ns.Alias, Timeout: timeout, Phase: last.Phase}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func PrintClusters(cluster *[]v1.StaroidCluster, orgs *[]v1.StaroidOrg) {
 	orgInfo := make(map[int64]*v1.StaroidOrg)
 	if orgs != nil {
-		for _, org := range *orgs {
-			orgInfo[org.ID] = &org
+		for i := range *orgs {
+			orgInfo[(*orgs)[i].ID] = &(*orgs)[i]
 		}
 	}
 
 	rows := make([]*[]string, 0)
 	for _, cluster := range *cluster {
-		org := orgInfo[cluster.OrgID]
-		rows = append(rows, &[]string{cluster.Name, fmt.Sprintf("%s/%s", org.Provider, org.Name), fmt.Sprintf("%s/%s", cluster.Ske.Cloud, cluster.Ske.Region)})
+		orgName := ""
+		if org, ok := orgInfo[cluster.OrgID]; ok {
+			orgName = fmt.Sprintf("%s/%s", org.Provider, org.Name)
+		}
+		rows = append(rows, &[]string{cluster.Name, orgName, fmt.Sprintf("%s/%s", cluster.Ske.Cloud, cluster.Ske.Region)})
 	}
 	header := []string{"NAME", "ORG", "SKE"}
 	PrintTable(&header, &rows)
 }
 
-func ClusterCmd(args []string) {
-	getCmd := flag.NewFlagSet("cluster", flag.ExitOnError)
+// OrgClusters is result of listing clusters of an org
+type OrgClusters struct {
+	Org      v1.StaroidOrg
+	Clusters []v1.StaroidCluster
+	Err      error
+}
+
+// GetClustersOfOrgs lists clusters of all orgs, at most concurrency orgs at a time.
+// Results are in the same order as orgs. Failure of an org does not affect others.
+func GetClustersOfOrgs(ctx context.Context, client *api.StaroidClient, orgs []v1.StaroidOrg, concurrency int) []OrgClusters {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]OrgClusters, len(orgs))
+	sem := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+	for i, org := range orgs {
+		wg.Add(1)
+		go func(i int, org v1.StaroidOrg) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i].Org = org
+			clusters, err := client.V1().Cluster().WithContext(ctx).WithOrg(org.Provider, org.Name).GetAll()
+			if err != nil {
+				results[i].Err = err
+				return
+			}
+			results[i].Clusters = *clusters
+		}(i, org)
+	}
+	wg.Wait()
+	return results
+}
+
+func ClusterCmd(ctx context.Context, args []string) {
+	clusterCmdFlag := flag.NewFlagSet("cluster", flag.ExitOnError)
+	orgName := clusterCmdFlag.String("org", settingDefault("org"), "organization (e.g. GITHUB/staroid). required by create, get and delete")
+	concurrency := clusterCmdFlag.Int("concurrency", 4, "number of orgs to list clusters from concurrently")
+	strict := clusterCmdFlag.Bool("strict", false, "exit with non-zero code when clusters of any org can not be listed")
+	cloud := clusterCmdFlag.String("cloud", "", "cloud of the SKE to create the cluster on (e.g. aws)")
+	region := clusterCmdFlag.String("region", "", "region of the SKE to create the cluster on (e.g. us-west2)")
+	wait := clusterCmdFlag.Bool("wait", false, "Wait (sync) for operation finish")
+	cascade := clusterCmdFlag.Bool("cascade", false, "delete namespaces of the cluster too")
+	yes := clusterCmdFlag.Bool("yes", false, "do not ask for confirmation")
+	timeout := clusterCmdFlag.Duration("timeout", constants.NsStartTimeoutSec*time.Second, "maximum time to wait with -wait or -cascade")
+	pollInterval := clusterCmdFlag.Duration("poll-interval", constants.StatusPollingIntervalSec*time.Second, "interval of polling status")
+
+	clusterCmdFlag.Parse(args)
+	cmdArgs := clusterCmdFlag.Args()
 
-	if len(args) < 1 {
+	if len(cmdArgs) < 1 {
 		ClusterCmdUsage()
 		os.Exit(1)
 	}
 
-	getCmd.Parse(args)
+	argName := ""
+	if len(cmdArgs) > 1 {
+		argName = cmdArgs[1]
+	}
 
-	switch args[0] {
-	case "list":
-		client := CreateClient()
-		orgs, err := client.V1().Org().GetAll()
+	switch cmdArgs[0] {
+	case "create":
+		if argName == "" || *cloud == "" || *region == "" {
+			fmt.Println("cluster -org <org> -cloud <cloud> -region <region> create <name>")
+			os.Exit(1)
+		}
+		client, org := clusterOrg(ctx, *orgName)
+
+		// validate before creating. see 'starctl ske list'
+		ske, err := client.V1().Ske().WithContext(ctx).Find(*cloud, *region)
 		if err != nil {
-			fmt.Printf("%v", err)
+			fmt.Printf("%v\n", err)
 			os.Exit(1)
 		}
 
-		allClusters := make([]v1.StaroidCluster, 0)
-		for _, org := range *orgs {
-			clusters, err := client.V1().Cluster().WithOrg(org.Provider, org.Name).GetAll()
+		cluster, err := client.V1().Cluster().
+			WithContext(ctx).
+			WithOrg(org.Provider, org.Name).
+			Create(argName, *ske)
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		lookupCache.Delete(clustersCacheKey(client, org))
+		PrintClusters(&[]v1.StaroidCluster{*cluster}, &[]v1.StaroidOrg{*org})
+	case "get":
+		if argName == "" {
+			ClusterCmdUsage()
+			os.Exit(1)
+		}
+		client, org := clusterOrg(ctx, *orgName)
+		cluster, err := GetClusterFromName(ctx, client, org, argName)
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		cluster, err = client.V1().Cluster().WithContext(ctx).WithOrg(org.Provider, org.Name).GetById(cluster.ID)
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		namespaces, err := client.V1().Namespace().WithContext(ctx).WithOrg(org.Provider, org.Name).WithClusterID(cluster.ID).GetAll()
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		PrintCluster(cluster, org, namespaces)
+	case "delete":
+		if argName == "" {
+			ClusterCmdUsage()
+			os.Exit(1)
+		}
+		client, org := clusterOrg(ctx, *orgName)
+		cluster, err := GetClusterFromName(ctx, client, org, argName)
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+
+		nsBuilder := client.V1().Namespace().WithContext(ctx).WithOrg(org.Provider, org.Name).WithClusterID(cluster.ID).WithPollInterval(*pollInterval)
+		all, err := nsBuilder.GetAll()
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		namespaces := activeNamespaces(all)
+		if len(*namespaces) > 0 && !*cascade {
+			fmt.Printf("Cluster %s has %d namespace(s). Delete them first or use -cascade flag\n", argName, len(*namespaces))
+			os.Exit(1)
+		}
+
+		if !*yes {
+			prompt := fmt.Sprintf("Delete cluster %s of %s/%s?", argName, org.Provider, org.Name)
+			if len(*namespaces) > 0 {
+				prompt = fmt.Sprintf("Delete cluster %s of %s/%s and its %d namespace(s)?", argName, org.Provider, org.Name, len(*namespaces))
+			}
+			if !Confirm(prompt) {
+				fmt.Printf("Canceled\n")
+				os.Exit(1)
+			}
+		}
+
+		if len(*namespaces) > 0 {
+			s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+			s.Prefix = fmt.Sprintf("deleting %d namespace(s) ... ", len(*namespaces))
+			s.Start()
+			err = DeleteNamespaces(ctx, nsBuilder, namespaces, *timeout)
+			s.Stop()
+			if err != nil {
+				fmt.Printf("%v\n", err)
+				os.Exit(waitExitCode(err))
+			}
+			for _, ns := range *namespaces {
+				lookupCache.Delete(namespaceCacheKey(client, cluster, ns.Alias))
+			}
+		}
+
+		builder := client.V1().Cluster().WithContext(ctx).WithOrg(org.Provider, org.Name).WithPollInterval(*pollInterval)
+		if err := builder.DeleteById(cluster.ID); err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		lookupCache.Delete(clustersCacheKey(client, org))
 
+		if *wait {
+			s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+			s.Prefix = fmt.Sprintf("deleting %s ... ", argName)
+			s.Start()
+			waitCtx, cancel := context.WithTimeout(ctx, *timeout)
+			err = builder.WaitForDeletion(waitCtx, cluster.ID)
+			cancel()
+			s.Stop()
+			if err == context.DeadlineExceeded && ctx.Err() == nil {
+				fmt.Printf("Timed out after %v waiting for deletion of cluster %s\n", *timeout, argName)
+				os.Exit(ExitCodeTimeout)
+			}
+			if err != nil && ctx.Err() == nil {
+				err = &WaitStatusError{Name: "cluster " + argName, Err: err}
+			}
 			if err != nil {
-				fmt.Printf("%v", err)
+				fmt.Printf("%v\n", err)
+				os.Exit(waitExitCode(err))
 			}
+			fmt.Printf("%s deleted\n", argName)
+		} else {
+			fmt.Printf("Deleting cluster %s\n", argName)
+		}
+	case "list":
+		client := CreateClient()
+		orgs, err := client.V1().Org().WithContext(ctx).GetAll()
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
 
-			allClusters = append(allClusters, *clusters...)
+		allClusters := make([]v1.StaroidCluster, 0)
+		failed := make([]*[]string, 0)
+		for _, result := range GetClustersOfOrgs(ctx, client, *orgs, *concurrency) {
+			if result.Err != nil {
+				failed = append(failed, &[]string{fmt.Sprintf("%s/%s", result.Org.Provider, result.Org.Name), result.Err.Error()})
+				continue
+			}
+			allClusters = append(allClusters, result.Clusters...)
 		}
 		PrintClusters(&allClusters, orgs)
+
+		if len(failed) > 0 {
+			fmt.Printf("\nFailed to list clusters of %d org(s)\n", len(failed))
+			header := []string{"ORG", "ERROR"}
+			PrintTable(&header, &failed)
+			if *strict {
+				os.Exit(1)
+			}
+		}
 	default:
 		ClusterCmdUsage()
 		os.Exit(1)
 	}
+}
+
+// clusterOrg creates client and finds org required by cluster subcommands
+func clusterOrg(ctx context.Context, orgName string) (*api.StaroidClient, *v1.StaroidOrg) {
+	if orgName == "" {
+		fmt.Println("'org' flag is missing")
+		os.Exit(1)
+	}
 
+	client := CreateClient()
+	org, err := GetOrgFromName(ctx, client, orgName)
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(1)
+	}
+	return client, org
 }
@@ -1,46 +1,149 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/staroids/starctl/pkg/api"
 	v1 "github.com/staroids/starctl/pkg/api/v1"
+	"github.com/staroids/starctl/pkg/cache"
 )
 
-func GetOrgFromName(client *api.StaroidClient, orgName string) (*v1.StaroidOrg, error) {
-	orgs, err := client.V1().Org().GetAll()
+// Exit codes
+const (
+	// ExitCodeFailure is exit code of failures other than below
+	ExitCodeFailure = 1
+	// ExitCodeTimeout is exit code when -wait times out. (2 is exit code of invalid flags)
+	ExitCodeTimeout = 3
+	// ExitCodeFailedPhase is exit code when a namespace is removed while waiting for another phase
+	ExitCodeFailedPhase = 4
+	// ExitCodeAPIError is exit code when an api request fails while waiting
+	ExitCodeAPIError = 5
+)
+
+// NewSignalContext returns a context that is cancelled on SIGINT or SIGTERM.
+// A second signal terminates the process immediately.
+func NewSignalContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sig := make(chan os.Signal, 2)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-sig:
+			cancel()
+		case <-ctx.Done():
+			return
+		}
+		<-sig
+		os.Exit(1)
+	}()
+	return ctx, cancel
+}
+
+// EnvInt returns integer value of environment variable name, or def when it is not set or invalid
+func EnvInt(name string, def int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
 	if err != nil {
-		return nil, err
+		return def
 	}
+	return value
+}
+
+// EnvDuration returns duration value (e.g. 30s) of environment variable name, or def when it is not set or invalid
+func EnvDuration(name string, def time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(name))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// lookupCache caches org, cluster and namespace alias lookups. nil when caching is disabled
+var lookupCache *cache.Cache
+
+// cacheKey builds a lookup cache key scoped to the api server and access token in use
+func cacheKey(client *api.StaroidClient, parts ...string) string {
+	tokenHash := sha256.Sum256([]byte(client.Auth.AccessToken()))
+	return fmt.Sprintf("%s|%s|%s", client.Auth.ApiServer(), hex.EncodeToString(tokenHash[:8]), strings.Join(parts, "/"))
+}
 
-	var org *v1.StaroidOrg = nil
+func findOrg(orgs *[]v1.StaroidOrg, orgName string) *v1.StaroidOrg {
 	for _, o := range *orgs {
 		if orgName == fmt.Sprintf("%s/%s", o.Provider, o.Name) {
-			org = &o
-			break
+			return &o
 		}
 	}
+	return nil
+}
 
-	if org == nil {
-		err = fmt.Errorf("Org '%s' not found\n", orgName)
+func findCluster(clusters *[]v1.StaroidCluster, clusterName string) *v1.StaroidCluster {
+	for _, c := range *clusters {
+		if c.Name == clusterName {
+			return &c
+		}
+	}
+	return nil
+}
+
+func GetOrgFromName(ctx context.Context, client *api.StaroidClient, orgName string) (*v1.StaroidOrg, error) {
+	key := cacheKey(client, "orgs")
+	cached := make([]v1.StaroidOrg, 0)
+	if lookupCache.Get(key, &cached) {
+		if org := findOrg(&cached, orgName); org != nil {
+			return org, nil
+		}
+	}
+
+	orgs, err := client.V1().Org().WithContext(ctx).GetAll()
+	if err != nil {
 		return nil, err
 	}
+	lookupCache.Set(key, orgs)
+
+	org := findOrg(orgs, orgName)
+	if org == nil {
+		names := make([]string, len(*orgs))
+		for i, o := range *orgs {
+			names[i] = fmt.Sprintf("%s/%s", o.Provider, o.Name)
+		}
+		if suggestions := Suggest(orgName, names); len(suggestions) > 0 {
+			return nil, fmt.Errorf("Org '%s' not found. Did you mean %s?", orgName, strings.Join(suggestions, " or "))
+		}
+		return nil, fmt.Errorf("Org '%s' not found. Run 'starctl org list' to see available orgs", orgName)
+	}
 
 	return org, nil
 }
 
-func GetClusterFromName(client *api.StaroidClient, org *v1.StaroidOrg, clusterName string) (*v1.StaroidCluster, error) {
-	clusters, err := client.V1().Cluster().WithOrg(org.Provider, org.Name).GetAll()
+func clustersCacheKey(client *api.StaroidClient, org *v1.StaroidOrg) string {
+	return cacheKey(client, "clusters", org.Provider, org.Name)
+}
+
+func GetClusterFromName(ctx context.Context, client *api.StaroidClient, org *v1.StaroidOrg, clusterName string) (*v1.StaroidCluster, error) {
+	key := clustersCacheKey(client, org)
+	cached := make([]v1.StaroidCluster, 0)
+	if lookupCache.Get(key, &cached) {
+		if cluster := findCluster(&cached, clusterName); cluster != nil {
+			return cluster, nil
+		}
+	}
+
+	clusters, err := client.V1().Cluster().WithContext(ctx).WithOrg(org.Provider, org.Name).GetAll()
 	if err != nil {
 		return nil, err
 	}
-	var cluster *v1.StaroidCluster = nil
-	for _, c := range *clusters {
-		if c.Name == clusterName {
-			cluster = &c
-			break
-		}
-	}
+	lookupCache.Set(key, clusters)
+
+	cluster := findCluster(clusters, clusterName)
 	if cluster == nil {
 		err = fmt.Errorf("Cluster '%s' not found\n", clusterName)
 		return nil, err
@@ -49,8 +152,25 @@ func GetClusterFromName(client *api.StaroidClient, org *v1.StaroidOrg, clusterNa
 	return cluster, nil
 }
 
-func GetNamespaceFromAlias(client *api.StaroidClient, org *v1.StaroidOrg, cluster *v1.StaroidCluster, nsAlias string) (*v1.StaroidNamespace, error) {
-	namespaces, err := client.V1().Namespace().WithOrg(org.Provider, org.Name).WithClusterID(cluster.ID).GetAll()
+func namespaceCacheKey(client *api.StaroidClient, cluster *v1.StaroidCluster, nsAlias string) string {
+	return cacheKey(client, "namespace", strconv.FormatInt(cluster.ID, 10), nsAlias)
+}
+
+func GetNamespaceFromAlias(ctx context.Context, client *api.StaroidClient, org *v1.StaroidOrg, cluster *v1.StaroidCluster, nsAlias string) (*v1.StaroidNamespace, error) {
+	builder := client.V1().Namespace().WithContext(ctx).WithOrg(org.Provider, org.Name).WithClusterID(cluster.ID)
+
+	// cached alias -> id mapping saves listing all namespaces
+	key := namespaceCacheKey(client, cluster, nsAlias)
+	var namespaceID int64
+	if lookupCache.Get(key, &namespaceID) {
+		ns, err := builder.GetById(namespaceID)
+		if err == nil && ns.Alias == nsAlias && ns.Phase != v1.NamespacePhaseRemoved {
+			return ns, nil
+		}
+		lookupCache.Delete(key)
+	}
+
+	namespaces, err := builder.GetAll()
 	if err != nil {
 		return nil, err
 	}
@@ -66,6 +186,15 @@ func GetNamespaceFromAlias(client *api.StaroidClient, org *v1.StaroidOrg, cluste
 		err = fmt.Errorf("Namespace alias '%s' not found\n", nsAlias)
 		return nil, err
 	}
+	lookupCache.Set(key, ns.ID)
 
 	return ns, nil
 }
+
+// Confirm asks a yes/no question on stdin. Anything but 'y' or 'yes' is no
+func Confirm(prompt string) bool {
+	fmt.Printf("%s [y/N] ", prompt)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
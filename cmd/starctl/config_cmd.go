@@ -0,0 +1,166 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/staroids/starctl/pkg/config"
+	"github.com/staroids/starctl/pkg/constants"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func ConfigCmdUsage() {
+	fmt.Fprintf(os.Stdout, "config [view|set|unset|explain] <key> <value>\n\n")
+	fmt.Fprintf(os.Stdout, "keys: current-profile, %s\n", strings.Join(config.ProfileKeys(), ", "))
+	fmt.Fprintf(os.Stdout, "set and unset modify the profile selected by -profile flag, or the current profile\n")
+	fmt.Fprintf(os.Stdout, "explain [-org] [-cluster] [-project] [-ns-alias] shows effective values and where they come from\n")
+}
+
+// ExplainConfig prints effective settings and their sources. args are flags of commands to take into account
+func ExplainConfig(args []string) {
+	explainFlag := flag.NewFlagSet("config explain", flag.ExitOnError)
+	for _, s := range settings {
+		explainFlag.String(s.key, "", s.key+" flag to take into account")
+	}
+	explainFlag.Parse(args)
+
+	header := []string{"KEY", "VALUE", "SOURCE"}
+	rows := make([]*[]string, 0)
+
+	profileSource := "default"
+	if *profileName != "" {
+		profileSource = "env " + constants.EnvStaroidProfile
+		flag.Visit(func(f *flag.Flag) {
+			if f.Name == "profile" {
+				profileSource = "flag"
+			}
+		})
+	} else if starctlConfig.CurrentProfile != "" {
+		profileSource = "current-profile"
+	}
+	rows = append(rows, &[]string{"profile", activeProfileName, profileSource})
+
+	contextSource := ""
+	if *contextName != "" {
+		contextSource = "env " + constants.EnvStarctlContext
+		flag.Visit(func(f *flag.Flag) {
+			if f.Name == "context" {
+				contextSource = "flag"
+			}
+		})
+	} else if activeContextName != "" {
+		contextSource = "current-context"
+	}
+	rows = append(rows, &[]string{"context", activeContextName, contextSource})
+
+	projectFile := ""
+	if activeProject != nil {
+		projectFile = activeProject.Path
+	}
+	rows = append(rows, &[]string{"project-file", projectFile, ""})
+
+	a := NewAuth()
+	apiServerSource := "default"
+	if os.Getenv(constants.EnvStaroidApiServer) != "" {
+		apiServerSource = "env " + constants.EnvStaroidApiServer
+	} else if activeProfile.ApiServer != "" {
+		apiServerSource = fmt.Sprintf("profile '%s'", activeProfileName)
+	}
+	rows = append(rows, &[]string{"api-server", a.ApiServer(), apiServerSource})
+	rows = append(rows, &[]string{"access-token", redactToken(a.AccessToken()), a.Source()})
+
+	for _, s := range settings {
+		value, source := resolveSetting(s.key)
+		explainFlag.Visit(func(f *flag.Flag) {
+			if f.Name == s.key {
+				value, source = f.Value.String(), "flag"
+			}
+		})
+		rows = append(rows, &[]string{s.key, value, source})
+	}
+
+	if activeProject != nil && len(activeProject.TunnelRemotes) > 0 {
+		rows = append(rows, &[]string{"tunnel-remotes", strings.Join(activeProject.TunnelRemotes, " "), "project file " + activeProject.Path})
+	}
+	PrintTable(&header, &rows)
+}
+
+// redactToken hides all but the last 4 characters of an access token
+func redactToken(token string) string {
+	if len(token) <= 8 {
+		return strings.Repeat("*", len(token))
+	}
+	return strings.Repeat("*", len(token)-4) + token[len(token)-4:]
+}
+
+func ConfigCmd(args []string) {
+	configCmdFlag := flag.NewFlagSet("config", flag.ExitOnError)
+	raw := configCmdFlag.Bool("raw", false, "show access tokens without redaction in 'view'")
+	configCmdFlag.Parse(args)
+	cmdArgs := configCmdFlag.Args()
+
+	if len(cmdArgs) < 1 {
+		ConfigCmdUsage()
+		os.Exit(1)
+	}
+
+	switch cmdArgs[0] {
+	case "explain":
+		ExplainConfig(cmdArgs[1:])
+	case "view":
+		view := config.Config{
+			CurrentProfile: starctlConfig.CurrentProfile,
+			Profiles:       make(map[string]*config.Profile),
+			CurrentContext: starctlConfig.CurrentContext,
+			Contexts:       starctlConfig.Contexts,
+		}
+		for name, p := range starctlConfig.Profiles {
+			copied := *p
+			if !*raw {
+				copied.AccessToken = redactToken(copied.AccessToken)
+			}
+			view.Profiles[name] = &copied
+		}
+
+		out, err := yaml.Marshal(&view)
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		path, _ := config.Path()
+		fmt.Printf("# %s (profile in use: %s)\n%s", path, activeProfileName, string(out))
+	case "set", "unset":
+		if (cmdArgs[0] == "set" && len(cmdArgs) != 3) || (cmdArgs[0] == "unset" && len(cmdArgs) != 2) {
+			ConfigCmdUsage()
+			os.Exit(1)
+		}
+		key := cmdArgs[1]
+		value := ""
+		if cmdArgs[0] == "set" {
+			value = cmdArgs[2]
+		}
+
+		if key == "current-profile" {
+			starctlConfig.CurrentProfile = value
+		} else {
+			p := starctlConfig.GetOrCreateProfile(activeProfileName)
+			if err := p.Set(key, value); err != nil {
+				fmt.Printf("%v\n", err)
+				os.Exit(1)
+			}
+			if *p == (config.Profile{}) {
+				delete(starctlConfig.Profiles, activeProfileName)
+			}
+		}
+
+		if err := starctlConfig.Save(); err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+	default:
+		ConfigCmdUsage()
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func ContextCmdUsage(flagSet *flag.FlagSet) {
+	fmt.Fprintf(os.Stdout, "context [set|use|list|current] <name>\n\n")
+	fmt.Fprintf(os.Stdout, "  context set <name> [flags]   create or update a context\n")
+	fmt.Fprintf(os.Stdout, "  context use <name>           make the context current\n")
+	fmt.Fprintf(os.Stdout, "  context list                 list contexts\n")
+	fmt.Fprintf(os.Stdout, "  context current              print name of the current context\n\n")
+	flagSet.PrintDefaults()
+}
+
+func ContextCmd(args []string) {
+	contextCmdFlag := flag.NewFlagSet("context set", flag.ExitOnError)
+	orgName := contextCmdFlag.String("org", "", "organization (e.g. GITHUB/staroid)")
+	clusterName := contextCmdFlag.String("cluster", "", "name of cluster")
+	nsAlias := contextCmdFlag.String("ns-alias", "", "namespace alias")
+
+	if len(args) < 1 {
+		ContextCmdUsage(contextCmdFlag)
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "set":
+		if len(args) < 2 {
+			ContextCmdUsage(contextCmdFlag)
+			os.Exit(1)
+		}
+		name := args[1]
+		contextCmdFlag.Parse(args[2:])
+		if len(contextCmdFlag.Args()) > 0 {
+			ContextCmdUsage(contextCmdFlag)
+			os.Exit(1)
+		}
+
+		// only flags given are changed. an empty value unsets it
+		c := starctlConfig.GetOrCreateContext(name)
+		contextCmdFlag.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "org":
+				c.Org = *orgName
+			case "cluster":
+				c.Cluster = *clusterName
+			case "ns-alias":
+				c.Namespace = *nsAlias
+			}
+		})
+		if err := starctlConfig.Save(); err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Context '%s' saved\n", name)
+	case "use":
+		if len(args) != 2 {
+			ContextCmdUsage(contextCmdFlag)
+			os.Exit(1)
+		}
+		if err := starctlConfig.UseContext(args[1]); err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		if err := starctlConfig.Save(); err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Switched to context '%s'\n", args[1])
+	case "list":
+		header := []string{"CURRENT", "NAME", "ORG", "CLUSTER", "NAMESPACE"}
+		rows := make([]*[]string, 0)
+		for _, name := range starctlConfig.ContextNames() {
+			c := starctlConfig.Context(name)
+			current := ""
+			if name == activeContextName {
+				current = "*"
+			}
+			rows = append(rows, &[]string{current, name, c.Org, c.Cluster, c.Namespace})
+		}
+		PrintTable(&header, &rows)
+	case "current":
+		if activeContextName == "" {
+			fmt.Printf("No current context\n")
+			os.Exit(1)
+		}
+		fmt.Printf("%s\n", activeContextName)
+	default:
+		ContextCmdUsage(contextCmdFlag)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	v1 "github.com/staroids/starctl/pkg/api/v1"
+	"github.com/staroids/starctl/pkg/auth"
+	"github.com/staroids/starctl/pkg/constants"
+)
+
+func LoginCmdUsage(flagSet *flag.FlagSet) {
+	fmt.Fprintf(os.Stdout, "login [flags]\n\n")
+	flagSet.PrintDefaults()
+}
+
+func LoginCmd(ctx context.Context, args []string) {
+	defaultAuthURL := os.Getenv(constants.EnvStaroidAuthURL)
+	if defaultAuthURL == "" {
+		defaultAuthURL = constants.AuthURL
+	}
+
+	loginCmdFlag := flag.NewFlagSet("login", flag.ExitOnError)
+	authURL := loginCmdFlag.String("auth-url", defaultAuthURL, "url of the login page (env "+constants.EnvStaroidAuthURL+")")
+	noBrowser := loginCmdFlag.Bool("no-browser", false, "print the login url instead of opening a browser")
+	timeout := loginCmdFlag.Duration("timeout", 5*time.Minute, "time to wait for login")
+	loginCmdFlag.Parse(args)
+
+	if len(loginCmdFlag.Args()) > 0 {
+		LoginCmdUsage(loginCmdFlag)
+		os.Exit(1)
+	}
+
+	flow := auth.LoginFlow{
+		AuthURL: *authURL,
+		Out:     os.Stdout,
+	}
+	if !*noBrowser {
+		flow.OpenBrowser = auth.OpenBrowser
+	}
+
+	loginCtx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+	token, err := flow.Run(loginCtx)
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(1)
+	}
+
+	// validate the token before saving
+	client := NewClient(auth.StaroidAuth{
+		Token:   token,
+		Profile: activeProfile,
+	})
+	orgs, err := client.V1().Org().WithContext(ctx).GetAll()
+	if v1.IsUnauthorized(err) {
+		fmt.Printf("Received access token is not valid\n")
+		os.Exit(1)
+	} else if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(1)
+	}
+
+	if credentialStore == nil {
+		fmt.Printf("Credential store is not available\n")
+		os.Exit(1)
+	}
+	if err := credentialStore.Set(activeProfileName, token); err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(1)
+	}
+	// do not leave a plaintext token behind
+	if p := starctlConfig.Profile(activeProfileName); p != nil && p.AccessToken != "" {
+		p.AccessToken = ""
+		if err := starctlConfig.Save(); err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Printf("Logged in to %s. %d org(s) accessible. Access token saved to profile '%s'.\n", client.Auth.ApiServer(), len(*orgs), activeProfileName)
+	if os.Getenv(constants.EnvStaroidAccessToken) != "" {
+		fmt.Printf("Warning: %s environment variable is set and takes precedence over the saved access token.\n", constants.EnvStaroidAccessToken)
+	}
+}
+
+func LogoutCmd(args []string) {
+	if len(args) > 0 {
+		fmt.Fprintf(os.Stdout, "logout\n")
+		os.Exit(1)
+	}
+
+	removed := false
+	if credentialStore != nil {
+		var err error
+		if removed, err = credentialStore.Remove(activeProfileName); err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if p := starctlConfig.Profile(activeProfileName); p != nil && p.AccessToken != "" {
+		p.AccessToken = ""
+		if err := starctlConfig.Save(); err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		removed = true
+	}
+
+	if !removed {
+		fmt.Printf("Not logged in with profile '%s'\n", activeProfileName)
+		os.Exit(1)
+	}
+	lookupCache.Clear()
+
+	// staroid api has no endpoint to revoke an access token
+	fmt.Printf("Access token removed from profile '%s'.\n", activeProfileName)
+	fmt.Printf("Warning: the access token is not revoked and is still valid. Revoke it from %s\n", constants.AccessTokensURL)
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/staroids/starctl/pkg/api"
+	v1 "github.com/staroids/starctl/pkg/api/v1"
+)
+
+// isGlob returns true when pattern has any path.Match meta character
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// SelectNamespaces returns namespaces matching any of patterns, or all namespaces when all is true,
+// in the order of namespaces. A pattern is an alias or a glob (see path.Match) of aliases.
+// phase filters selected namespaces further when not empty. An alias without glob must exist.
+// Namespaces being deleted are not matched by globs or all.
+func SelectNamespaces(namespaces []v1.StaroidNamespace, patterns []string, all bool, phase v1.NamespacePhase) ([]v1.StaroidNamespace, error) {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("Invalid alias pattern '%s': %v", pattern, err)
+		}
+		if isGlob(pattern) {
+			continue
+		}
+		found := false
+		for _, ns := range namespaces {
+			if ns.Alias == pattern {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Namespace alias '%s' not found", pattern)
+		}
+	}
+
+	selected := make([]v1.StaroidNamespace, 0)
+	for _, ns := range namespaces {
+		if phase != "" && ns.Phase != phase {
+			continue
+		}
+		// namespaces being deleted are selected only by exact alias
+		deleted := ns.Status == v1.NamespaceStatusInactive
+		matched := all && !deleted
+		for _, pattern := range patterns {
+			if pattern == ns.Alias || (!deleted && isGlob(pattern)) {
+				if ok, _ := path.Match(pattern, ns.Alias); ok {
+					matched = true
+					break
+				}
+			}
+		}
+		if matched {
+			selected = append(selected, ns)
+		}
+	}
+	return selected, nil
+}
+
+// NamespaceResult is result of an operation on a namespace in a bulk operation
+type NamespaceResult struct {
+	Namespace v1.StaroidNamespace
+	// Result describes what is done, when Err is nil
+	Result string
+	Err    error
+}
+
+// applyNamespaceOp starts, stops or deletes the namespace, the same way as the single namespace commands do.
+// With wait, it waits up to timeout until the namespace is in the phase the operation leads to
+func applyNamespaceOp(ctx context.Context, builder *v1.NamespaceRequestBuilder, ns v1.StaroidNamespace, op string, wait bool, timeout time.Duration) NamespaceResult {
+	var predicate v1.NamespacePredicate
+	result := NamespaceResult{Namespace: ns}
+	updated := &ns
+	var err error
+
+	switch op {
+	case "start":
+		predicate = v1.PhaseIn(v1.NamespacePhaseRunning)
+		switch ns.Status {
+		case v1.NamespaceStatusInactive:
+			result.Err = fmt.Errorf("Can not start %s once deleted", ns.Alias)
+			return result
+		case v1.NamespaceStatusPause:
+			updated, err = builder.StartById(ns.ID)
+			result.Result = "started"
+		default:
+			result.Result = "already started"
+		}
+	case "stop":
+		predicate = v1.PhaseIn(v1.NamespacePhasePaused)
+		switch ns.Status {
+		case v1.NamespaceStatusInactive:
+			result.Err = fmt.Errorf("Can not stop %s once deleted", ns.Alias)
+			return result
+		case v1.NamespaceStatusActive:
+			updated, err = builder.StopById(ns.ID)
+			result.Result = "stopped"
+		default:
+			result.Result = "already stopped"
+		}
+	case "delete":
+		predicate = v1.PhaseIn(v1.NamespacePhaseRemoved)
+		updated, err = builder.DeleteById(ns.ID)
+		result.Result = "deleted"
+	default:
+		result.Err = fmt.Errorf("Unknown operation %s", op)
+		return result
+	}
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Namespace = *updated
+
+	if wait {
+		last, err := waitNamespace(ctx, builder, updated, predicate, timeout)
+		result.Namespace = *last
+		result.Err = err
+	}
+	return result
+}
+
+// BulkNamespaceOp applies op to namespaces, at most concurrency namespaces at a time.
+// Each namespace is operated with its own builder from newBuilder, as builders are not safe for concurrent use.
+// Results are in the same order as namespaces. Failure of a namespace does not affect others.
+func BulkNamespaceOp(ctx context.Context, newBuilder func() *v1.NamespaceRequestBuilder, namespaces []v1.StaroidNamespace, op string, wait bool, timeout time.Duration, concurrency int) []NamespaceResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]NamespaceResult, len(namespaces))
+	sem := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+	for i, ns := range namespaces {
+		wg.Add(1)
+		go func(i int, ns v1.StaroidNamespace) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = applyNamespaceOp(ctx, newBuilder(), ns, op, wait, timeout)
+		}(i, ns)
+	}
+	wg.Wait()
+	return results
+}
+
+// BulkNamespaceOptions are flags of a bulk namespace operation
+type BulkNamespaceOptions struct {
+	Patterns    []string
+	All         bool
+	Phase       v1.NamespacePhase
+	DryRun      bool
+	Yes         bool
+	Wait        bool
+	Concurrency int
+	// Timeout and PollInterval are used when Wait is true
+	Timeout      time.Duration
+	PollInterval time.Duration
+}
+
+// bulkNamespaceCmd runs start, stop or delete on selected namespaces and prints a result table.
+// When the operation fails on any namespace, exits with exit code of the first failed namespace (see waitExitCode)
+func bulkNamespaceCmd(ctx context.Context, client *api.StaroidClient, org *v1.StaroidOrg, cluster *v1.StaroidCluster, op string, opts BulkNamespaceOptions) {
+	newBuilder := func() *v1.NamespaceRequestBuilder {
+		return client.V1().Namespace().
+			WithContext(ctx).
+			WithOrg(org.Provider, org.Name).
+			WithClusterID(cluster.ID).
+			WithPollInterval(opts.PollInterval)
+	}
+	namespaces, err := newBuilder().GetAll()
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(1)
+	}
+
+	selected, err := SelectNamespaces(*namespaces, opts.Patterns, opts.All, opts.Phase)
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(1)
+	}
+	if len(selected) == 0 {
+		fmt.Printf("No namespace matched\n")
+		return
+	}
+
+	if opts.DryRun || (op == "delete" && !opts.Yes) {
+		header := []string{"ALIAS", "NAME", "PHASE", "STATUS"}
+		rows := make([]*[]string, 0)
+		for _, ns := range selected {
+			rows = append(rows, &[]string{ns.Alias, ns.Namespace, string(ns.Phase), ns.Status})
+		}
+		PrintTable(&header, &rows)
+	}
+	if opts.DryRun {
+		fmt.Printf("\nWould %s %d namespace(s)\n", op, len(selected))
+		return
+	}
+	if op == "delete" && !opts.Yes {
+		if !Confirm(fmt.Sprintf("\nDelete %d namespace(s) above?", len(selected))) {
+			fmt.Printf("Canceled\n")
+			os.Exit(1)
+		}
+	}
+
+	failed := 0
+	exitCode := 0
+	header := []string{"ALIAS", "NAME", "PHASE", "RESULT"}
+	rows := make([]*[]string, 0)
+	for _, result := range BulkNamespaceOp(ctx, newBuilder, selected, op, opts.Wait, opts.Timeout, opts.Concurrency) {
+		ns := result.Namespace
+		message := result.Result
+		if result.Err != nil {
+			failed++
+			if exitCode == 0 {
+				exitCode = waitExitCode(result.Err)
+			}
+			message = fmt.Sprintf("failed: %v", result.Err)
+		} else if op == "delete" {
+			lookupCache.Delete(namespaceCacheKey(client, cluster, ns.Alias))
+		}
+		rows = append(rows, &[]string{ns.Alias, ns.Namespace, string(ns.Phase), message})
+	}
+	PrintTable(&header, &rows)
+
+	if failed > 0 {
+		fmt.Printf("\nFailed to %s %d of %d namespace(s)\n", op, failed, len(selected))
+		os.Exit(exitCode)
+	}
+}
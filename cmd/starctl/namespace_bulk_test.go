@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	v1 "github.com/staroids/starctl/pkg/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectNamespaces(t *testing.T) {
+	namespaces := []v1.StaroidNamespace{
+		{Alias: "dev", Phase: v1.NamespacePhaseRunning},
+		{Alias: "pr-1", Phase: v1.NamespacePhaseRunning},
+		{Alias: "pr-2", Phase: v1.NamespacePhasePaused},
+		{Alias: "prod", Phase: v1.NamespacePhaseRunning},
+		{Alias: "pr-3", Phase: v1.NamespacePhaseRunning, Status: v1.NamespaceStatusInactive},
+	}
+	aliases := func(selected []v1.StaroidNamespace) []string {
+		names := make([]string, len(selected))
+		for i, ns := range selected {
+			names[i] = ns.Alias
+		}
+		return names
+	}
+
+	tests := []struct {
+		patterns []string
+		all      bool
+		phase    v1.NamespacePhase
+		expected []string
+		err      string
+	}{
+		{[]string{"dev"}, false, "", []string{"dev"}, ""},
+		{[]string{"pr-*"}, false, "", []string{"pr-1", "pr-2"}, ""},
+		{[]string{"prod", "dev"}, false, "", []string{"dev", "prod"}, ""},
+		{[]string{"pr-*"}, false, v1.NamespacePhaseRunning, []string{"pr-1"}, ""},
+		{nil, true, v1.NamespacePhaseRunning, []string{"dev", "pr-1", "prod"}, ""},
+		{[]string{"test-*"}, false, "", []string{}, ""},
+		{[]string{"pr-3"}, false, "", []string{"pr-3"}, ""},
+		{[]string{"test"}, false, "", nil, "Namespace alias 'test' not found"},
+		{[]string{"pr-["}, false, "", nil, "Invalid alias pattern 'pr-[': syntax error in pattern"},
+	}
+
+	for _, tt := range tests {
+		selected, err := SelectNamespaces(namespaces, tt.patterns, tt.all, tt.phase)
+		if tt.err != "" {
+			assert.EqualError(t, err, tt.err)
+			continue
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, tt.expected, aliases(selected), tt.patterns)
+	}
+}
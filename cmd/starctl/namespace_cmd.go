@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
@@ -12,7 +15,11 @@ import (
 )
 
 func NamespaceCmdUsage() {
-	fmt.Fprintf(os.Stdout, "namespace [flags] [create|list|get|start|stop|delete] <alias>\n")
+	fmt.Fprintf(os.Stdout, "namespace [flags] [create|list|get|describe|start|stop|delete] <alias>\n")
+	fmt.Fprintf(os.Stdout, "namespace [flags] watch [alias...]\n")
+	fmt.Fprintf(os.Stdout, "namespace [flags] [start|stop|delete] [alias|pattern...]\n")
+	fmt.Fprintf(os.Stdout, "namespace -f <spec file> create\n")
+	fmt.Fprintf(os.Stdout, "alias defaults to namespace of the current context\n")
 }
 
 func PrintNamespaces(cluster *[]v1.StaroidCluster, orgs *[]v1.StaroidOrg) {
@@ -32,15 +39,266 @@ func PrintNamespaces(cluster *[]v1.StaroidCluster, orgs *[]v1.StaroidOrg) {
 	PrintTable(&header, &rows)
 }
 
-func NamespaceCmd(args []string) {
+// PrintNamespace prints all fields of the namespace and its services with public endpoints.
+// resourcesErr is printed instead of services when resources of the namespace can not be listed
+func PrintNamespace(ns *v1.StaroidNamespace, org *v1.StaroidOrg, cluster *v1.StaroidCluster, resources *v1.StaroidNamespaceResources, resourcesErr error) {
+	project := "-"
+	if ns.Commit != nil {
+		project = ns.Commit.String()
+	}
+
+	fmt.Printf("Alias:    %s\n", ns.Alias)
+	fmt.Printf("Name:     %s\n", ns.Namespace)
+	fmt.Printf("ID:       %d\n", ns.ID)
+	fmt.Printf("Org:      %s/%s\n", org.Provider, org.Name)
+	fmt.Printf("Cluster:  %s\n", cluster.Name)
+	fmt.Printf("Type:     %s\n", ns.Type)
+	fmt.Printf("Phase:    %s\n", ns.Phase)
+	fmt.Printf("Status:   %s\n", ns.Status)
+	fmt.Printf("Access:   %s\n", ns.Access)
+	fmt.Printf("URL:      %s\n", ns.URL)
+	fmt.Printf("Project:  %s\n", project)
+
+	if resourcesErr != nil {
+		fmt.Printf("Shell:    unknown\n")
+		fmt.Printf("\nCan't list services: %v\n", resourcesErr)
+		return
+	}
+
+	shell := "not running"
+	rows := make([]*[]string, 0)
+	for _, service := range resources.Services.Items {
+		if service.Labels[constants.K8S_LABEL_KEY_RESOURCE_SYSTEM] == constants.K8S_LABEL_VALUE_RESOURCE_SYSTEM_SHELL {
+			shell = "running"
+		}
+		if len(service.Spec.Ports) == 0 {
+			rows = append(rows, &[]string{service.Name, "-", "-", "-"})
+			continue
+		}
+		for _, port := range service.Spec.Ports {
+			endpoint := "-"
+			if strings.HasPrefix(ns.URL, "https://") {
+				endpoint = ns.ServiceURL(service.Name, int(port.Port))
+			}
+			rows = append(rows, &[]string{service.Name, port.Name, fmt.Sprintf("%d", port.Port), endpoint})
+		}
+	}
+	fmt.Printf("Shell:    %s\n", shell)
+
+	fmt.Printf("\nServices: %d\n", len(resources.Services.Items))
+	if len(rows) == 0 {
+		return
+	}
+	header := []string{"SERVICE", "PORT NAME", "PORT", "ENDPOINT"}
+	PrintTable(&header, &rows)
+}
+
+// phaseReached returns true when all watched namespaces are in phase.
+// Every alias in aliases must exist unless phase is REMOVED
+func phaseReached(event *v1.NamespaceListEvent, aliases []string, phase v1.NamespacePhase) bool {
+	if phase == v1.NamespacePhaseRemoved {
+		return len(event.Namespaces) == 0
+	}
+	for _, ns := range event.Namespaces {
+		if ns.Phase != phase {
+			return false
+		}
+	}
+	for _, alias := range aliases {
+		found := false
+		for _, ns := range event.Namespaces {
+			if ns.Alias == alias {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return len(event.Namespaces) > 0
+}
+
+// WatchNamespaces prints phase and status changes of namespaces having aliases (all namespaces when empty)
+// with timestamps until ctx is done. When until is not empty, it returns once all watched namespaces are in the phase.
+// With table, the current states are printed as a namespace table first.
+func WatchNamespaces(ctx context.Context, builder *v1.NamespaceRequestBuilder, aliases []string, until v1.NamespacePhase, table bool) error {
+	headerPrinted := false
+	first := true
+	for event := range builder.WatchAll(ctx, aliases...) {
+		if event.Err != nil {
+			return event.Err
+		}
+
+		if first && table {
+			header := []string{"ALIAS", "NAME", "TYPE", "PHASE"}
+			rows := make([]*[]string, 0)
+			for _, ns := range event.Namespaces {
+				rows = append(rows, &[]string{ns.Alias, ns.Namespace, ns.Type, string(ns.Phase)})
+			}
+			PrintTable(&header, &rows)
+		} else {
+			if !headerPrinted {
+				fmt.Printf("%-25s  %-20s  %-10s  %s\n", "TIME", "ALIAS", "PHASE", "STATUS")
+				headerPrinted = true
+			}
+			for _, ns := range event.Changed {
+				fmt.Printf("%-25s  %-20s  %-10s  %s\n", event.Time.Format(time.RFC3339), ns.Alias, ns.Phase, ns.Status)
+			}
+		}
+		first = false
+
+		if until != "" && phaseReached(&event, aliases, until) {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+// WaitTimeoutError is returned when a namespace does not reach the expected phase before timeout
+type WaitTimeoutError struct {
+	Alias   string
+	Timeout time.Duration
+	// Phase is the last known phase
+	Phase v1.NamespacePhase
+}
+
+func (e *WaitTimeoutError) Error() string {
+	return fmt.Sprintf("Timed out after %v waiting for %s. Last phase: %s", e.Timeout, e.Alias, e.Phase)
+}
+
+// FailedPhaseError is returned when a namespace lands in a phase it never leaves for the expected one.
+// Staroid api has no failure phase. A namespace that fails, or is deleted meanwhile, ends up REMOVED
+type FailedPhaseError struct {
+	Alias string
+	Phase v1.NamespacePhase
+}
+
+func (e *FailedPhaseError) Error() string {
+	return fmt.Sprintf("%s is in %s phase", e.Alias, e.Phase)
+}
+
+// WaitStatusError is returned when status can not be read while waiting, by an api error or a transport failure
+type WaitStatusError struct {
+	Name string
+	Err  error
+}
+
+func (e *WaitStatusError) Error() string {
+	return fmt.Sprintf("Can't get status of %s: %v", e.Name, e.Err)
+}
+
+func (e *WaitStatusError) Unwrap() error {
+	return e.Err
+}
+
+// waitExitCode returns exit code for an error of waitNamespace
+func waitExitCode(err error) int {
+	var timeoutErr *WaitTimeoutError
+	var phaseErr *FailedPhaseError
+	var statusErr *WaitStatusError
+	var apiErr *v1.StaroidAPIError
+	switch {
+	case errors.As(err, &timeoutErr):
+		return ExitCodeTimeout
+	case errors.As(err, &phaseErr):
+		return ExitCodeFailedPhase
+	case errors.As(err, &statusErr), errors.As(err, &apiErr):
+		return ExitCodeAPIError
+	}
+	return ExitCodeFailure
+}
+
+// waitNamespace waits until predicate is true for ns and returns the last known state of ns.
+// It fails with *WaitTimeoutError when timeout elapses and with *FailedPhaseError when ns is removed
+// while predicate expects another phase
+func waitNamespace(ctx context.Context, builder *v1.NamespaceRequestBuilder, ns *v1.StaroidNamespace, predicate v1.NamespacePredicate, timeout time.Duration) (*v1.StaroidNamespace, error) {
+	failed := func(n *v1.StaroidNamespace) bool {
+		return n.Phase == v1.NamespacePhaseRemoved && !predicate(n)
+	}
+	done := func(n *v1.StaroidNamespace) bool {
+		return predicate(n) || failed(n)
+	}
+
+	last := ns
+	if !done(ns) {
+		waitCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		polled, err := builder.WaitFor(waitCtx, ns.ID, done)
+		if polled != nil {
+			last = polled
+		}
+
+		switch {
+		case ctx.Err() != nil:
+			return last, ctx.Err()
+		case err == context.DeadlineExceeded:
+			return last, &WaitTimeoutError{Alias: ns.Alias, Timeout: timeout, Phase: last.Phase}
+		case v1.IsNotFound(err) && predicate(&v1.StaroidNamespace{Phase: v1.NamespacePhaseRemoved}):
+			// removed namespace may not be found at all
+			removed := *last
+			removed.Phase = v1.NamespacePhaseRemoved
+			return &removed, nil
+		case err != nil:
+			return last, &WaitStatusError{Name: ns.Alias, Err: err}
+		}
+	}
+
+	if failed(last) {
+		return last, &FailedPhaseError{Alias: ns.Alias, Phase: last.Phase}
+	}
+	return last, nil
+}
+
+// WaitNamespace shows a spinner until predicate is true for ns, and prints the phase reached.
+// It exits with ExitCodeTimeout when timeout elapses, ExitCodeFailedPhase when ns is removed meanwhile,
+// or ExitCodeAPIError when status can not be read. Returns the last known state of ns
+func WaitNamespace(ctx context.Context, builder *v1.NamespaceRequestBuilder, ns *v1.StaroidNamespace, message string, predicate v1.NamespacePredicate, timeout time.Duration) *v1.StaroidNamespace {
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Prefix = message
+	if !predicate(ns) {
+		s.Start()
+	}
+	last, err := waitNamespace(ctx, builder, ns, predicate, timeout)
+	s.Stop()
+
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(waitExitCode(err))
+	}
+	fmt.Printf("%s reached %s phase\n", last.Alias, last.Phase)
+	return last
+}
+
+func NamespaceCmd(ctx context.Context, args []string) {
 	namespaceCmdFlag := flag.NewFlagSet("namespace", flag.ExitOnError)
-	orgName := namespaceCmdFlag.String("org", "", "organization (e.g. GITHUB/staroid)")
-	clusterName := namespaceCmdFlag.String("cluster", "", "name of cluster")
-	commitLoc := namespaceCmdFlag.String("project", "GITHUB/staroids/namespace:master", "project:branch(#commit) (e.g. GITHUB/staroid/app:master, GITHUB/staroid/app:trunk#d10abcd)")
+	orgName := namespaceCmdFlag.String("org", settingDefault("org"), "organization (e.g. GITHUB/staroid)")
+	clusterName := namespaceCmdFlag.String("cluster", settingDefault("cluster"), "name of cluster")
+	commitLoc := namespaceCmdFlag.String("project", settingDefault("project"), "project:branch(#commit) (e.g. GITHUB/staroid/app:master, GITHUB/staroid/app:trunk#d10abcd)")
 	wait := namespaceCmdFlag.Bool("wait", false, "Wait (sync) for operation finish")
+	watch := namespaceCmdFlag.Bool("watch", false, "keep printing phase changes after list")
+	untilPhase := namespaceCmdFlag.String("until", "", "exit watch when all watched namespaces are in the phase (e.g. RUNNING)")
+	all := namespaceCmdFlag.Bool("all", false, "start, stop or delete all namespaces in the cluster")
+	phaseFilter := namespaceCmdFlag.String("phase", "", "start, stop or delete only namespaces in the phase (e.g. RUNNING)")
+	dryRun := namespaceCmdFlag.Bool("dry-run", false, "print namespaces to start, stop or delete without doing it")
+	yes := namespaceCmdFlag.Bool("yes", false, "do not ask for confirmation of deleting multiple namespaces")
+	concurrency := namespaceCmdFlag.Int("concurrency", 4, "number of namespaces to start, stop or delete concurrently")
+	pollInterval := namespaceCmdFlag.Duration("poll-interval", constants.StatusPollingIntervalSec*time.Second, "interval of polling namespace status")
+	timeout := namespaceCmdFlag.Duration("timeout", constants.NsStartTimeoutSec*time.Second, "maximum time to wait with -wait")
+	specFile := namespaceCmdFlag.String("f", "", "YAML or JSON file describing namespaces to create")
 
 	namespaceCmdFlag.Parse(args)
 
+	// org and cluster of a spec file default to the flags
+	if *specFile != "" {
+		if namespaceCmdFlag.NArg() != 1 || namespaceCmdFlag.Arg(0) != "create" {
+			fmt.Println("-f flag is only supported by create")
+			os.Exit(1)
+		}
+		createNamespacesFromSpec(ctx, *specFile, *orgName, *clusterName, *wait, *timeout, *pollInterval)
+		return
+	}
+
 	if *orgName == "" {
 		fmt.Println("'org' flag is missing")
 		os.Exit(1)
@@ -58,25 +316,87 @@ func NamespaceCmd(args []string) {
 		os.Exit(1)
 	}
 
-	argAlias := ""
+	var until v1.NamespacePhase
+	if *untilPhase != "" {
+		phase, err := v1.ParseNamespacePhase(*untilPhase)
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		until = phase
+	}
+
+	var phase v1.NamespacePhase
+	if *phaseFilter != "" {
+		p, err := v1.ParseNamespacePhase(*phaseFilter)
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		phase = p
+	}
+
+	argAlias := settingDefault("ns-alias")
 	if len(cmdArgs) > 1 {
 		argAlias = cmdArgs[1]
 	}
 
+	// more than a single alias selects namespaces to operate on in bulk
+	patterns := cmdArgs[1:]
+	bulkFlags := *all || phase != "" || *dryRun
+	bulk := bulkFlags || len(patterns) > 1 || (len(patterns) == 1 && isGlob(patterns[0]))
+	switch cmdArgs[0] {
+	case "start", "stop", "delete":
+	case "watch":
+		// watch takes multiple aliases, but does not select namespaces
+		if bulkFlags {
+			NamespaceCmdUsage()
+			os.Exit(2)
+		}
+		bulk = false
+	default:
+		if bulk {
+			NamespaceCmdUsage()
+			os.Exit(2)
+		}
+	}
+
 	staroidClient := CreateClient()
 
-	org, err := GetOrgFromName(staroidClient, *orgName)
+	org, err := GetOrgFromName(ctx, staroidClient, *orgName)
 	if err != nil {
 		fmt.Printf("%v\n", err)
 		os.Exit(1)
 	}
 
-	cluster, err := GetClusterFromName(staroidClient, org, *clusterName)
+	cluster, err := GetClusterFromName(ctx, staroidClient, org, *clusterName)
 	if err != nil {
 		fmt.Printf("%v\n", err)
 		os.Exit(1)
 	}
 
+	if bulk {
+		if !*all && len(patterns) == 0 {
+			if argAlias == "" {
+				NamespaceCmdUsage()
+				os.Exit(1)
+			}
+			patterns = []string{argAlias}
+		}
+		bulkNamespaceCmd(ctx, staroidClient, org, cluster, cmdArgs[0], BulkNamespaceOptions{
+			Patterns:     patterns,
+			All:          *all,
+			Phase:        phase,
+			DryRun:       *dryRun,
+			Yes:          *yes,
+			Wait:         *wait,
+			Concurrency:  *concurrency,
+			Timeout:      *timeout,
+			PollInterval: *pollInterval,
+		})
+		return
+	}
+
 	switch cmdArgs[0] {
 	case "create":
 		if argAlias == "" {
@@ -89,6 +409,7 @@ func NamespaceCmd(args []string) {
 			os.Exit(1)
 		}
 		ns, err := staroidClient.V1().Namespace().
+			WithContext(ctx).
 			WithOrg(org.Provider, org.Name).
 			WithClusterID(cluster.ID).
 			WithCommit(commit).
@@ -97,35 +418,19 @@ func NamespaceCmd(args []string) {
 			fmt.Printf("%v\n", err)
 			os.Exit(1)
 		}
+		lookupCache.Set(namespaceCacheKey(staroidClient, cluster, argAlias), ns.ID)
 
 		if *wait {
-			s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
-			s.Prefix = fmt.Sprintf("%s created. starting ... ", argAlias)
-			s.Start()
-			now := time.Now()
-			timeout := now.Add(time.Second * constants.NsStartTimeoutSec)
-			for now.Before(timeout) {
-				if ns.Phase != "SCHEDULED" && ns.Phase != "STARTING" {
-					break
-				}
-				time.Sleep(constants.StatusPollingIntervalSec * time.Second)
-				ns, err = staroidClient.V1().Namespace().
-					WithOrg(org.Provider, org.Name).
-					WithClusterID(cluster.ID).
-					WithCommit(commit).
-					GetById(ns.ID)
-
-				if err != nil {
-					fmt.Printf("Can't get status\n")
-					os.Exit(1)
-				}
-				now = time.Now()
-			}
-			s.Stop()
+			builder := staroidClient.V1().Namespace().
+				WithContext(ctx).
+				WithOrg(org.Provider, org.Name).
+				WithClusterID(cluster.ID).
+				WithPollInterval(*pollInterval)
+			ns = WaitNamespace(ctx, builder, ns, fmt.Sprintf("%s created. starting ... ", argAlias), v1.PhaseIn(v1.NamespacePhaseRunning, v1.NamespacePhasePaused), *timeout)
 		}
 		header := []string{"ALIAS", "NAME", "TYPE", "PHASE"}
 		rows := make([]*[]string, 0)
-		rows = append(rows, &[]string{ns.Alias, ns.Namespace, ns.Type, ns.Phase})
+		rows = append(rows, &[]string{ns.Alias, ns.Namespace, ns.Type, string(ns.Phase)})
 		PrintTable(&header, &rows)
 	case "delete":
 		if argAlias == "" {
@@ -133,84 +438,71 @@ func NamespaceCmd(args []string) {
 			os.Exit(1)
 		}
 
-		ns, err := staroidClient.V1().Namespace().
+		ns, err := GetNamespaceFromAlias(ctx, staroidClient, org, cluster, argAlias)
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+
+		ns, err = staroidClient.V1().Namespace().
+			WithContext(ctx).
 			WithOrg(org.Provider, org.Name).
 			WithClusterID(cluster.ID).
-			Delete(argAlias)
+			DeleteById(ns.ID)
 		if err != nil {
 			fmt.Printf("%v\n", err)
 			os.Exit(1)
 		}
+		lookupCache.Delete(namespaceCacheKey(staroidClient, cluster, argAlias))
 
 		if *wait {
-			s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
-			s.Prefix = fmt.Sprintf("deleting %s ... ", argAlias)
-			s.Start()
-			now := time.Now()
-			timeout := now.Add(time.Second * constants.NsStartTimeoutSec)
-			for now.Before(timeout) {
-				if ns.Phase == "REMOVED" {
-					break
-				}
-				time.Sleep(constants.StatusPollingIntervalSec * time.Second)
-				ns, err = staroidClient.V1().Namespace().
-					WithOrg(org.Provider, org.Name).
-					WithClusterID(cluster.ID).
-					GetById(ns.ID)
-
-				if err != nil {
-					fmt.Printf("Can't get status\n")
-					os.Exit(1)
-				}
-				now = time.Now()
-			}
-			s.Stop()
-			fmt.Sprintf("%s deleted", argAlias)
+			builder := staroidClient.V1().Namespace().
+				WithContext(ctx).
+				WithOrg(org.Provider, org.Name).
+				WithClusterID(cluster.ID).
+				WithPollInterval(*pollInterval)
+			WaitNamespace(ctx, builder, ns, fmt.Sprintf("deleting %s ... ", argAlias), v1.PhaseIn(v1.NamespacePhaseRemoved), *timeout)
 		} else {
 			header := []string{"ALIAS", "NAME", "TYPE", "PHASE"}
 			rows := make([]*[]string, 0)
-			rows = append(rows, &[]string{ns.Alias, ns.Namespace, ns.Type, ns.Phase})
+			rows = append(rows, &[]string{ns.Alias, ns.Namespace, ns.Type, string(ns.Phase)})
 			PrintTable(&header, &rows)
 		}
-	case "get":
+	case "get", "describe":
 		if argAlias == "" {
 			NamespaceCmdUsage()
 			os.Exit(1)
 		}
-		ns, err := staroidClient.V1().Namespace().
-			WithOrg(org.Provider, org.Name).
-			WithClusterID(cluster.ID).
-			Get(argAlias)
+		ns, err := GetNamespaceFromAlias(ctx, staroidClient, org, cluster, argAlias)
 		if err != nil {
 			fmt.Printf("%v\n", err)
 			os.Exit(1)
 		}
 
-		header := []string{"ALIAS", "NAME", "TYPE", "PHASE"}
-		rows := make([]*[]string, 0)
-		rows = append(rows, &[]string{ns.Alias, ns.Namespace, ns.Type, ns.Phase})
-		PrintTable(&header, &rows)
+		resources, err := staroidClient.V1().Namespace().
+			WithContext(ctx).
+			WithName(ns.Namespace).
+			GetAllResources()
+		PrintNamespace(ns, org, cluster, resources, err)
 	case "start":
 		if argAlias == "" {
 			NamespaceCmdUsage()
 			os.Exit(1)
 		}
-		ns, err := staroidClient.V1().Namespace().
-			WithOrg(org.Provider, org.Name).
-			WithClusterID(cluster.ID).
-			Get(argAlias)
+		ns, err := GetNamespaceFromAlias(ctx, staroidClient, org, cluster, argAlias)
 		if err != nil {
 			fmt.Printf("%v\n", err)
 			os.Exit(1)
 		}
 
-		if ns.Status == "INACTIVE" {
+		if ns.Status == v1.NamespaceStatusInactive {
 			fmt.Printf("Can not start %v once deleted", argAlias)
 			os.Exit(1)
 		}
 
-		if ns.Status == "PAUSE" {
+		if ns.Status == v1.NamespaceStatusPause {
 			ns, err = staroidClient.V1().Namespace().
+				WithContext(ctx).
 				WithOrg(org.Provider, org.Name).
 				WithClusterID(cluster.ID).
 				StartById(ns.ID)
@@ -221,54 +513,36 @@ func NamespaceCmd(args []string) {
 		}
 
 		if *wait {
-			s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
-			s.Prefix = fmt.Sprintf("%s starting ... ", argAlias)
-			s.Start()
-			now := time.Now()
-			timeout := now.Add(time.Second * constants.NsStartTimeoutSec)
-			for now.Before(timeout) {
-				if ns.Phase != "SCHEDULED" && ns.Phase != "STARTING" && ns.Phase != "PAUSED" {
-					break
-				}
-				time.Sleep(constants.StatusPollingIntervalSec * time.Second)
-				ns, err = staroidClient.V1().Namespace().
-					WithOrg(org.Provider, org.Name).
-					WithClusterID(cluster.ID).
-					GetById(ns.ID)
-
-				if err != nil {
-					fmt.Printf("Can't get status\n")
-					os.Exit(1)
-				}
-				now = time.Now()
-			}
-			s.Stop()
+			builder := staroidClient.V1().Namespace().
+				WithContext(ctx).
+				WithOrg(org.Provider, org.Name).
+				WithClusterID(cluster.ID).
+				WithPollInterval(*pollInterval)
+			ns = WaitNamespace(ctx, builder, ns, fmt.Sprintf("%s starting ... ", argAlias), v1.PhaseIn(v1.NamespacePhaseRunning), *timeout)
 		}
 		header := []string{"ALIAS", "NAME", "TYPE", "PHASE"}
 		rows := make([]*[]string, 0)
-		rows = append(rows, &[]string{ns.Alias, ns.Namespace, ns.Type, ns.Phase})
+		rows = append(rows, &[]string{ns.Alias, ns.Namespace, ns.Type, string(ns.Phase)})
 		PrintTable(&header, &rows)
 	case "stop":
 		if argAlias == "" {
 			NamespaceCmdUsage()
 			os.Exit(1)
 		}
-		ns, err := staroidClient.V1().Namespace().
-			WithOrg(org.Provider, org.Name).
-			WithClusterID(cluster.ID).
-			Get(argAlias)
+		ns, err := GetNamespaceFromAlias(ctx, staroidClient, org, cluster, argAlias)
 		if err != nil {
 			fmt.Printf("%v\n", err)
 			os.Exit(1)
 		}
 
-		if ns.Status == "INACTIVE" {
+		if ns.Status == v1.NamespaceStatusInactive {
 			fmt.Printf("Can not stop %v once deleted", argAlias)
 			os.Exit(1)
 		}
 
-		if ns.Status == "ACTIVE" {
+		if ns.Status == v1.NamespaceStatusActive {
 			ns, err = staroidClient.V1().Namespace().
+				WithContext(ctx).
 				WithOrg(org.Provider, org.Name).
 				WithClusterID(cluster.ID).
 				StopById(ns.ID)
@@ -279,35 +553,35 @@ func NamespaceCmd(args []string) {
 		}
 
 		if *wait {
-			s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
-			s.Prefix = fmt.Sprintf("%s stopping ... ", argAlias)
-			s.Start()
-			now := time.Now()
-			timeout := now.Add(time.Second * constants.NsStartTimeoutSec)
-			for now.Before(timeout) {
-				if ns.Phase == "PAUSED" {
-					break
-				}
-				time.Sleep(constants.StatusPollingIntervalSec * time.Second)
-				ns, err = staroidClient.V1().Namespace().
-					WithOrg(org.Provider, org.Name).
-					WithClusterID(cluster.ID).
-					GetById(ns.ID)
-
-				if err != nil {
-					fmt.Printf("Can't get status\n")
-					os.Exit(1)
-				}
-				now = time.Now()
-			}
-			s.Stop()
+			builder := staroidClient.V1().Namespace().
+				WithContext(ctx).
+				WithOrg(org.Provider, org.Name).
+				WithClusterID(cluster.ID).
+				WithPollInterval(*pollInterval)
+			ns = WaitNamespace(ctx, builder, ns, fmt.Sprintf("%s stopping ... ", argAlias), v1.PhaseIn(v1.NamespacePhasePaused), *timeout)
 		}
 		header := []string{"ALIAS", "NAME", "TYPE", "PHASE"}
 		rows := make([]*[]string, 0)
-		rows = append(rows, &[]string{ns.Alias, ns.Namespace, ns.Type, ns.Phase})
+		rows = append(rows, &[]string{ns.Alias, ns.Namespace, ns.Type, string(ns.Phase)})
 		PrintTable(&header, &rows)
+	case "watch":
+		builder := staroidClient.V1().Namespace().
+			WithOrg(org.Provider, org.Name).
+			WithClusterID(cluster.ID).
+			WithPollInterval(*pollInterval)
+		watchNamespaces(ctx, builder, cmdArgs[1:], until, false)
 	case "list":
+		if *watch {
+			builder := staroidClient.V1().Namespace().
+				WithOrg(org.Provider, org.Name).
+				WithClusterID(cluster.ID).
+				WithPollInterval(*pollInterval)
+			watchNamespaces(ctx, builder, nil, until, true)
+			return
+		}
+
 		namespaces, err := staroidClient.V1().Namespace().
+			WithContext(ctx).
 			WithOrg(org.Provider, org.Name).
 			WithClusterID(cluster.ID).
 			GetAll()
@@ -319,7 +593,7 @@ func NamespaceCmd(args []string) {
 		header := []string{"ALIAS", "NAME", "TYPE", "PHASE"}
 		rows := make([]*[]string, 0)
 		for _, ns := range *namespaces {
-			rows = append(rows, &[]string{ns.Alias, ns.Namespace, ns.Type, ns.Phase})
+			rows = append(rows, &[]string{ns.Alias, ns.Namespace, ns.Type, string(ns.Phase)})
 		}
 		PrintTable(&header, &rows)
 	default:
@@ -327,3 +601,16 @@ func NamespaceCmd(args []string) {
 		os.Exit(1)
 	}
 }
+
+// watchNamespaces runs WatchNamespaces and exits on failure.
+// Interrupting a watch without target phase is not a failure
+func watchNamespaces(ctx context.Context, builder *v1.NamespaceRequestBuilder, aliases []string, until v1.NamespacePhase, table bool) {
+	err := WatchNamespaces(ctx, builder, aliases, until, table)
+	if err == context.Canceled && until == "" {
+		return
+	}
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(waitExitCode(err))
+	}
+}
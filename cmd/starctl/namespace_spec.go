@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	v1 "github.com/staroids/starctl/pkg/api/v1"
+	"github.com/staroids/starctl/pkg/config"
+)
+
+// createNamespacesFromSpec creates namespaces described in the spec file, in order.
+// All specs are validated before creating any namespace. Exits on the first failure
+func createNamespacesFromSpec(ctx context.Context, path string, defaultOrg string, defaultCluster string, wait bool, timeout time.Duration, pollInterval time.Duration) {
+	specs, err := config.LoadNamespaceSpecs(path, defaultOrg, defaultCluster)
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(1)
+	}
+
+	client := CreateClient()
+	header := []string{"ALIAS", "NAME", "ORG", "CLUSTER", "PHASE"}
+	rows := make([]*[]string, 0)
+	fail := func(spec config.NamespaceSpec, err error, exitCode int) {
+		if len(rows) > 0 {
+			PrintTable(&header, &rows)
+			fmt.Println()
+		}
+		fmt.Printf("Can't create %s: %v\n", spec.Alias, err)
+		os.Exit(exitCode)
+	}
+
+	for _, spec := range specs {
+		org, err := GetOrgFromName(ctx, client, spec.Org)
+		if err != nil {
+			fail(spec, err, ExitCodeFailure)
+		}
+		cluster, err := GetClusterFromName(ctx, client, org, spec.Cluster)
+		if err != nil {
+			fail(spec, err, ExitCodeFailure)
+		}
+
+		builder := client.V1().Namespace().
+			WithContext(ctx).
+			WithOrg(org.Provider, org.Name).
+			WithClusterID(cluster.ID).
+			WithPollInterval(pollInterval).
+			WithCommit(&v1.Commit{
+				Provider: spec.Project.Provider,
+				Owner:    spec.Project.Owner,
+				Repo:     spec.Project.Repo,
+				Branch:   spec.Project.Branch,
+				Commit:   spec.Project.Commit,
+			})
+		ns, err := builder.Create(spec.Alias)
+		if err != nil {
+			fail(spec, err, ExitCodeFailure)
+		}
+		lookupCache.Set(namespaceCacheKey(client, cluster, spec.Alias), ns.ID)
+
+		predicate := v1.PhaseIn(v1.NamespacePhaseRunning)
+		if spec.State == config.NamespaceStatePaused {
+			predicate = v1.PhaseIn(v1.NamespacePhasePaused)
+			if ns, err = builder.StopById(ns.ID); err != nil {
+				fail(spec, err, ExitCodeFailure)
+			}
+		}
+
+		if wait {
+			ns, err = waitNamespace(ctx, builder, ns, predicate, timeout)
+			if err != nil {
+				fail(spec, err, waitExitCode(err))
+			}
+		}
+		rows = append(rows, &[]string{ns.Alias, ns.Namespace, spec.Org, spec.Cluster, string(ns.Phase)})
+	}
+	PrintTable(&header, &rows)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/staroids/starctl/pkg/api"
+	v1 "github.com/staroids/starctl/pkg/api/v1"
+)
+
+func OrgCmdUsage() {
+	fmt.Fprintf(os.Stdout, "org [flags] [list|get] <PROVIDER/name>\n")
+}
+
+// OrgSummary is an org with its clusters and number of namespaces in each cluster
+type OrgSummary struct {
+	Org      v1.StaroidOrg
+	Clusters []v1.StaroidCluster
+	// Namespaces is number of namespaces by cluster id
+	Namespaces map[int64]int
+	Err        error
+}
+
+// NamespaceCount returns number of namespaces in all clusters of the org
+func (s *OrgSummary) NamespaceCount() int {
+	n := 0
+	for _, count := range s.Namespaces {
+		n += count
+	}
+	return n
+}
+
+// GetOrgSummaries lists clusters of orgs and counts namespaces in them,
+// making at most concurrency requests at a time. Results are in the same order as orgs.
+func GetOrgSummaries(ctx context.Context, client *api.StaroidClient, orgs []v1.StaroidOrg, concurrency int) []OrgSummary {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	summaries := make([]OrgSummary, len(orgs))
+	mu := sync.Mutex{}
+	sem := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+	for i, result := range GetClustersOfOrgs(ctx, client, orgs, concurrency) {
+		summaries[i] = OrgSummary{
+			Org:        result.Org,
+			Clusters:   result.Clusters,
+			Namespaces: make(map[int64]int),
+			Err:        result.Err,
+		}
+		for _, cluster := range result.Clusters {
+			wg.Add(1)
+			go func(s *OrgSummary, cluster v1.StaroidCluster) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				namespaces, err := client.V1().Namespace().
+					WithContext(ctx).
+					WithOrg(s.Org.Provider, s.Org.Name).
+					WithClusterID(cluster.ID).
+					GetAll()
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					if s.Err == nil {
+						s.Err = fmt.Errorf("Can't list namespaces of cluster %s: %v", cluster.Name, err)
+					}
+					return
+				}
+				s.Namespaces[cluster.ID] = len(*namespaces)
+			}(&summaries[i], cluster)
+		}
+	}
+	wg.Wait()
+	return summaries
+}
+
+func OrgCmd(ctx context.Context, args []string) {
+	orgCmdFlag := flag.NewFlagSet("org", flag.ExitOnError)
+	concurrency := orgCmdFlag.Int("concurrency", 4, "number of requests to make concurrently")
+
+	orgCmdFlag.Parse(args)
+	cmdArgs := orgCmdFlag.Args()
+
+	if len(cmdArgs) < 1 {
+		OrgCmdUsage()
+		os.Exit(1)
+	}
+
+	switch cmdArgs[0] {
+	case "list":
+		client := CreateClient()
+		orgs, err := client.V1().Org().WithContext(ctx).GetAll()
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+
+		header := []string{"ORG", "ID", "PROVIDER", "CLUSTERS", "NAMESPACES"}
+		rows := make([]*[]string, 0)
+		failed := make([]*[]string, 0)
+		for _, s := range GetOrgSummaries(ctx, client, *orgs, *concurrency) {
+			name := fmt.Sprintf("%s/%s", s.Org.Provider, s.Org.Name)
+			if s.Err != nil {
+				rows = append(rows, &[]string{name, fmt.Sprintf("%d", s.Org.ID), s.Org.Provider, "-", "-"})
+				failed = append(failed, &[]string{name, s.Err.Error()})
+				continue
+			}
+			rows = append(rows, &[]string{name, fmt.Sprintf("%d", s.Org.ID), s.Org.Provider, fmt.Sprintf("%d", len(s.Clusters)), fmt.Sprintf("%d", s.NamespaceCount())})
+		}
+		PrintTable(&header, &rows)
+
+		if len(failed) > 0 {
+			fmt.Printf("\nFailed to count clusters and namespaces of %d org(s)\n", len(failed))
+			header := []string{"ORG", "ERROR"}
+			PrintTable(&header, &failed)
+		}
+	case "get":
+		if len(cmdArgs) != 2 {
+			OrgCmdUsage()
+			os.Exit(1)
+		}
+		client := CreateClient()
+		org, err := GetOrgFromName(ctx, client, cmdArgs[1])
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+
+		s := GetOrgSummaries(ctx, client, []v1.StaroidOrg{*org}, *concurrency)[0]
+		if s.Err != nil {
+			fmt.Printf("%v\n", s.Err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Name:        %s/%s\n", org.Provider, org.Name)
+		fmt.Printf("ID:          %d\n", org.ID)
+		fmt.Printf("Provider:    %s\n", org.Provider)
+		fmt.Printf("Clusters:    %d\n", len(s.Clusters))
+		fmt.Printf("Namespaces:  %d\n", s.NamespaceCount())
+		if len(s.Clusters) == 0 {
+			return
+		}
+
+		fmt.Println()
+		header := []string{"CLUSTER", "SKE", "NAMESPACES"}
+		rows := make([]*[]string, 0)
+		for _, cluster := range s.Clusters {
+			rows = append(rows, &[]string{cluster.Name, fmt.Sprintf("%s/%s", cluster.Ske.Cloud, cluster.Ske.Region), fmt.Sprintf("%d", s.Namespaces[cluster.ID])})
+		}
+		PrintTable(&header, &rows)
+	default:
+		OrgCmdUsage()
+		os.Exit(1)
+	}
+}
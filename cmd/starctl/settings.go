@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/staroids/starctl/pkg/config"
+	"github.com/staroids/starctl/pkg/constants"
+)
+
+// activeProject is the nearest .starctl.yaml from the working directory. nil when there's none
+var activeProject *config.Project
+
+// setting is a default of command line flags shared by commands.
+// Value is looked up from environment variable, context selected by -context flag (or STARCTL_CONTEXT),
+// project file, current context, profile and def in order.
+type setting struct {
+	key     string
+	env     string
+	project func(p *config.Project) string
+	context func(c *config.Context) string
+	profile func(p *config.Profile) string
+	def     string
+}
+
+var settings = []setting{
+	{
+		key:     "org",
+		env:     constants.EnvStaroidOrg,
+		project: func(p *config.Project) string { return p.Org },
+		context: func(c *config.Context) string { return c.Org },
+		profile: func(p *config.Profile) string { return p.Org },
+	},
+	{
+		key:     "cluster",
+		env:     constants.EnvStaroidCluster,
+		project: func(p *config.Project) string { return p.Cluster },
+		context: func(c *config.Context) string { return c.Cluster },
+		profile: func(p *config.Profile) string { return p.Cluster },
+	},
+	{
+		key:     "project",
+		env:     constants.EnvStaroidProject,
+		project: func(p *config.Project) string { return p.Project },
+		def:     "GITHUB/staroids/namespace:master",
+	},
+	{
+		key:     "ns-alias",
+		env:     constants.EnvStaroidNsAlias,
+		project: func(p *config.Project) string { return p.NsAlias },
+		context: func(c *config.Context) string { return c.Namespace },
+	},
+}
+
+func findSetting(key string) *setting {
+	for i := range settings {
+		if settings[i].key == key {
+			return &settings[i]
+		}
+	}
+	panic(fmt.Sprintf("unknown setting %s", key))
+}
+
+// resolveSetting returns value of the setting when the flag is not given, and where the value comes from
+func resolveSetting(key string) (string, string) {
+	s := findSetting(key)
+	if v := os.Getenv(s.env); v != "" {
+		return v, "env " + s.env
+	}
+	// a context asked for explicitly wins over the project file. the current context does not
+	fromContext := func() (string, bool) {
+		if s.context == nil || activeContext == nil {
+			return "", false
+		}
+		v := s.context(activeContext)
+		return v, v != ""
+	}
+	if activeContextExplicit {
+		if v, ok := fromContext(); ok {
+			return v, fmt.Sprintf("context '%s'", activeContextName)
+		}
+	}
+	if s.project != nil && activeProject != nil {
+		if v := s.project(activeProject); v != "" {
+			return v, "project file " + activeProject.Path
+		}
+	}
+	if v, ok := fromContext(); ok {
+		return v, fmt.Sprintf("context '%s'", activeContextName)
+	}
+	if s.profile != nil && activeProfile != nil {
+		if v := s.profile(activeProfile); v != "" {
+			return v, fmt.Sprintf("profile '%s'", activeProfileName)
+		}
+	}
+	if s.def != "" {
+		return s.def, "default"
+	}
+	return "", ""
+}
+
+// settingDefault returns value of the setting to use as default of its flag
+func settingDefault(key string) string {
+	v, _ := resolveSetting(key)
+	return v
+}
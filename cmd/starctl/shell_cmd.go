@@ -1,19 +1,23 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+
+	v1 "github.com/staroids/starctl/pkg/api/v1"
 )
 
 func ShellCmdUsage() {
 	fmt.Fprintf(os.Stdout, "shell [flags] [start|stop] <namespace alias>\n")
+	fmt.Fprintf(os.Stdout, "namespace alias defaults to namespace of the current context\n")
 }
 
-func ShellCmd(args []string) {
+func ShellCmd(ctx context.Context, args []string) {
 	shellCmdFlag := flag.NewFlagSet("shell", flag.ExitOnError)
-	orgName := shellCmdFlag.String("org", "", "organization (e.g. GITHUB/staroid)")
-	clusterName := shellCmdFlag.String("cluster", "", "name of cluster")
+	orgName := shellCmdFlag.String("org", settingDefault("org"), "organization (e.g. GITHUB/staroid)")
+	clusterName := shellCmdFlag.String("cluster", settingDefault("cluster"), "name of cluster")
 
 	shellCmdFlag.Parse(args)
 
@@ -34,20 +38,20 @@ func ShellCmd(args []string) {
 		os.Exit(1)
 	}
 
-	argAlias := ""
+	argAlias := settingDefault("ns-alias")
 	if len(cmdArgs) > 1 {
 		argAlias = cmdArgs[1]
 	}
 
 	staroidClient := CreateClient()
 
-	org, err := GetOrgFromName(staroidClient, *orgName)
+	org, err := GetOrgFromName(ctx, staroidClient, *orgName)
 	if err != nil {
 		fmt.Printf("%v\n", err)
 		os.Exit(1)
 	}
 
-	cluster, err := GetClusterFromName(staroidClient, org, *clusterName)
+	cluster, err := GetClusterFromName(ctx, staroidClient, org, *clusterName)
 	if err != nil {
 		fmt.Printf("%v\n", err)
 		os.Exit(1)
@@ -59,21 +63,19 @@ func ShellCmd(args []string) {
 			ShellCmdUsage()
 			os.Exit(1)
 		}
-		ns, err := staroidClient.V1().Namespace().
-			WithOrg(org.Provider, org.Name).
-			WithClusterID(cluster.ID).
-			Get(argAlias)
+		ns, err := GetNamespaceFromAlias(ctx, staroidClient, org, cluster, argAlias)
 		if err != nil {
 			fmt.Printf("%v\n", err)
 			os.Exit(1)
 		}
 
-		if ns.Phase != "RUNNING" {
+		if ns.Phase != v1.NamespacePhaseRunning {
 			fmt.Printf("Namespace %v is not running", argAlias)
 			os.Exit(1)
 		}
 
 		err = staroidClient.V1().Namespace().
+			WithContext(ctx).
 			WithOrg(org.Provider, org.Name).
 			WithClusterID(cluster.ID).
 			ShellStartById(ns.ID)
@@ -86,21 +88,19 @@ func ShellCmd(args []string) {
 			ShellCmdUsage()
 			os.Exit(1)
 		}
-		ns, err := staroidClient.V1().Namespace().
-			WithOrg(org.Provider, org.Name).
-			WithClusterID(cluster.ID).
-			Get(argAlias)
+		ns, err := GetNamespaceFromAlias(ctx, staroidClient, org, cluster, argAlias)
 		if err != nil {
 			fmt.Printf("%v\n", err)
 			os.Exit(1)
 		}
 
-		if ns.Phase != "RUNNING" {
+		if ns.Phase != v1.NamespacePhaseRunning {
 			fmt.Printf("Namespace %v is not running", argAlias)
 			os.Exit(1)
 		}
 
 		err = staroidClient.V1().Namespace().
+			WithContext(ctx).
 			WithOrg(org.Provider, org.Name).
 			WithClusterID(cluster.ID).
 			ShellStopById(ns.ID)
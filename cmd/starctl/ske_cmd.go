@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func SkeCmdUsage() {
+	fmt.Fprintf(os.Stdout, "ske [flags] [list]\n")
+}
+
+func SkeCmd(ctx context.Context, args []string) {
+	skeCmdFlag := flag.NewFlagSet("ske", flag.ExitOnError)
+	cloud := skeCmdFlag.String("cloud", "", "show SKEs of the cloud only (e.g. aws)")
+	region := skeCmdFlag.String("region", "", "show SKEs of the region only (e.g. us-west2)")
+	available := skeCmdFlag.Bool("available", false, "show SKEs available for new clusters only")
+
+	skeCmdFlag.Parse(args)
+	cmdArgs := skeCmdFlag.Args()
+
+	if len(cmdArgs) != 1 {
+		SkeCmdUsage()
+		os.Exit(1)
+	}
+
+	switch cmdArgs[0] {
+	case "list":
+		client := CreateClient()
+		skes, err := client.V1().Ske().
+			WithContext(ctx).
+			WithCloud(*cloud).
+			WithRegion(*region).
+			WithAvailableOnly(*available).
+			GetAll()
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+
+		header := []string{"NAME", "CLOUD", "REGION", "AVAILABLE"}
+		rows := make([]*[]string, 0)
+		for _, ske := range *skes {
+			available := "unknown"
+			if ske.Available != nil {
+				available = fmt.Sprintf("%t", *ske.Available)
+			}
+			rows = append(rows, &[]string{ske.ID, ske.Cloud, ske.Region, available})
+		}
+		PrintTable(&header, &rows)
+	default:
+		SkeCmdUsage()
+		os.Exit(1)
+	}
+}
@@ -6,25 +6,91 @@ import (
 	"os"
 
 	"github.com/staroids/starctl/pkg/api"
+	"github.com/staroids/starctl/pkg/api/transport"
 	"github.com/staroids/starctl/pkg/auth"
+	"github.com/staroids/starctl/pkg/config"
 	"github.com/staroids/starctl/pkg/constants"
 )
 
+// global flags
+var (
+	maxAttempts    = flag.Int("max-attempts", EnvInt(constants.EnvStaroidMaxAttempts, transport.DefaultRetryPolicy().MaxAttempts), "max attempts of idempotent api requests. 1 disables retry (env "+constants.EnvStaroidMaxAttempts+")")
+	requestTimeout = flag.Duration("request-timeout", EnvDuration(constants.EnvStaroidRequestTimeout, 0), "timeout of an api request including retries (e.g. 30s). 0 means no timeout (env "+constants.EnvStaroidRequestTimeout+")")
+	caCert         = flag.String("ca-cert", os.Getenv(constants.EnvStaroidCACert), "PEM file of additional CA certificates to trust (env "+constants.EnvStaroidCACert+")")
+	clientCert     = flag.String("client-cert", os.Getenv(constants.EnvStaroidClientCert), "PEM file of TLS client certificate (env "+constants.EnvStaroidClientCert+")")
+	clientKey      = flag.String("client-key", os.Getenv(constants.EnvStaroidClientKey), "PEM file of TLS client key (env "+constants.EnvStaroidClientKey+")")
+	verbosity      int
+	curl           = flag.Bool("curl", false, "print curl command equivalent to each api request")
+	noCache        = flag.Bool("no-cache", false, "do not use cached org, cluster and namespace alias lookups")
+	profileName    = flag.String("profile", os.Getenv(constants.EnvStaroidProfile), "name of the profile in config file to use (env "+constants.EnvStaroidProfile+")")
+	contextName    = flag.String("context", os.Getenv(constants.EnvStarctlContext), "name of the context in config file to use instead of the current context (env "+constants.EnvStarctlContext+")")
+)
+
+var (
+	// starctlConfig is content of the config file
+	starctlConfig *config.Config
+	// activeProfileName is name of the profile selected by -profile flag or current-profile of the config
+	activeProfileName string
+	// activeProfile is the selected profile. Empty profile when not exists in the config file
+	activeProfile *config.Profile
+	// activeContextName is name of the context selected by -context flag or current-context of the config. Empty when none
+	activeContextName string
+	// activeContextExplicit is true when the context is selected by -context flag or STARCTL_CONTEXT, not by current-context
+	activeContextExplicit bool
+	// activeContext is the selected context. Empty context when none is selected
+	activeContext *config.Context
+)
+
+func init() {
+	verbosityUsage := "log api requests to stderr. 1: method, url, status, latency. 2: +headers. 3: +body"
+	flag.IntVar(&verbosity, "v", 0, verbosityUsage)
+	flag.IntVar(&verbosity, "verbosity", 0, verbosityUsage)
+}
+
 var usage = func() {
 	fmt.Fprintf(os.Stdout, "Usage of %s:\n", os.Args[0])
+	fmt.Fprintf(os.Stdout, "  starctl [flags] [auth|cache|cluster|config|context|login|logout|namespace|org|shell|ske|tunnel|version] ...\n\n")
 	flag.PrintDefaults()
 }
 
+// NewAuth returns auth of the selected profile
+func NewAuth() auth.StaroidAuth {
+	return auth.StaroidAuth{
+		Credentials: credentialStore,
+		ProfileName: activeProfileName,
+		Profile:     activeProfile,
+	}
+}
+
 func CreateClient() *api.StaroidClient {
-	auth := auth.StaroidAuth{}
+	auth := NewAuth()
 	err := auth.CheckAuth()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
 
+	return NewClient(auth)
+}
+
+// NewClient creates api client configured by global flags
+func NewClient(auth auth.StaroidAuth) *api.StaroidClient {
+	retry := transport.DefaultRetryPolicy()
+	retry.MaxAttempts = *maxAttempts
+
+	tlsConfig, err := transport.LoadTLSConfig(*caCert, *clientCert, *clientKey)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
 	client := api.StaroidClient{
-		Auth: auth,
+		Auth:      auth,
+		Retry:     retry,
+		Timeout:   *requestTimeout,
+		TLSConfig: tlsConfig,
+		Verbosity: verbosity,
+		TraceCurl: *curl,
 	}
 	return &client
 }
@@ -74,20 +140,85 @@ func PrintTable(header *[]string, rows *[]*[]string) {
 }
 
 func main() {
-	if len(os.Args) < 2 {
+	flag.Usage = usage
+	flag.Parse()
+	args := flag.Args()
+
+	if len(args) < 1 {
 		usage()
 		os.Exit(1)
 	}
 
-	switch os.Args[1] {
+	var err error
+	starctlConfig, err = config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	activeProfileName = starctlConfig.ProfileName(*profileName)
+	activeProfile = starctlConfig.Profile(activeProfileName)
+	// credential store is optional. run without it when config dir is not available
+	credentialStore, _ = NewCredentialStore()
+	if activeProfile == nil {
+		// a profile may only have an access token in the credential store.
+		// commands creating profiles accept a profile that does not exist yet
+		if *profileName != "" && !hasStoredToken(activeProfileName) && args[0] != "auth" && args[0] != "config" && args[0] != "login" {
+			fmt.Fprintf(os.Stderr, "Profile '%s' not found\n", *profileName)
+			os.Exit(1)
+		}
+		activeProfile = &config.Profile{}
+	}
+
+	activeContextName = starctlConfig.ContextName(*contextName)
+	activeContextExplicit = *contextName != ""
+	activeContext = starctlConfig.Context(activeContextName)
+	if activeContext == nil {
+		if activeContextName != "" && args[0] != "context" {
+			fmt.Fprintf(os.Stderr, "Context '%s' not found\n", activeContextName)
+			os.Exit(1)
+		}
+		activeContext = &config.Context{}
+	}
+
+	activeProject, err = config.LoadProject()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	if !*noCache {
+		// caching is best effort. run without cache when config dir is not available
+		lookupCache, _ = NewLookupCache()
+	}
+
+	ctx, cancel := NewSignalContext()
+	defer cancel()
+
+	switch args[0] {
+	case "auth":
+		AuthCmd(ctx, args[1:])
+	case "cache":
+		CacheCmd(args[1:])
 	case "cluster":
-		ClusterCmd(os.Args[2:])
+		ClusterCmd(ctx, args[1:])
+	case "config":
+		ConfigCmd(args[1:])
+	case "context":
+		ContextCmd(args[1:])
+	case "login":
+		LoginCmd(ctx, args[1:])
+	case "logout":
+		LogoutCmd(args[1:])
 	case "namespace":
-		NamespaceCmd(os.Args[2:])
+		NamespaceCmd(ctx, args[1:])
+	case "org":
+		OrgCmd(ctx, args[1:])
 	case "shell":
-		ShellCmd(os.Args[2:])
+		ShellCmd(ctx, args[1:])
+	case "ske":
+		SkeCmd(ctx, args[1:])
 	case "tunnel":
-		TunnelCmd(os.Args[2:])
+		TunnelCmd(ctx, args[1:])
 	case "version":
 		fmt.Printf("%s\n", constants.Version)
 		os.Exit(0)
@@ -0,0 +1,697 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/staroids/starctl/pkg/api/fake"
+	v1 "github.com/staroids/starctl/pkg/api/v1"
+	"github.com/staroids/starctl/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const envRunMain = "STARCTL_TEST_RUN_MAIN"
+
+// TestMain runs main() instead of tests when the test binary is re-executed by runStarctl
+func TestMain(m *testing.M) {
+	if os.Getenv(envRunMain) == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// starctlCommand returns command running starctl in a subprocess against the fake server
+func starctlCommand(server *fake.Server, args ...string) *exec.Cmd {
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(),
+		envRunMain+"=1",
+		constants.EnvStaroidApiServer+"="+server.URL,
+		constants.EnvStaroidAccessToken+"=test-token",
+	)
+	return cmd
+}
+
+// runStarctl runs starctl in a subprocess against the fake server and returns its output and exit code
+func runStarctl(t *testing.T, server *fake.Server, args ...string) (string, int) {
+	out, err := starctlCommand(server, args...).CombinedOutput()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return string(out), exitErr.ExitCode()
+	}
+	assert.Nil(t, err)
+	return string(out), 0
+}
+
+// newFakeServer starts fake api server and points starctl config dir to a temporary directory
+func newFakeServer(t *testing.T) *fake.Server {
+	server := fake.NewServer()
+	server.SetToken("test-token")
+	t.Cleanup(server.Close)
+
+	configDir, err := ioutil.TempDir("", "starctl-test")
+	assert.Nil(t, err)
+	os.Setenv(constants.EnvStarctlConfigDir, configDir)
+	t.Cleanup(func() {
+		os.Unsetenv(constants.EnvStarctlConfigDir)
+		os.RemoveAll(configDir)
+	})
+
+	org := server.AddOrg("GITHUB", "staroids")
+	server.AddCluster(org, "default", "aws", "us-west2")
+	return server
+}
+
+func TestClusterList(t *testing.T) {
+	server := newFakeServer(t)
+
+	out, code := runStarctl(t, server, "cluster", "list")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "default  GITHUB/staroids  aws/us-west2")
+}
+
+func TestNamespaceCreateAndList(t *testing.T) {
+	server := newFakeServer(t)
+
+	out, code := runStarctl(t, server, "namespace", "-org", "GITHUB/staroids", "-cluster", "default", "create", "dev")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "SCHEDULED")
+
+	out, code = runStarctl(t, server, "namespace", "-org", "GITHUB/staroids", "-cluster", "default", "list")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, 2, len(strings.Split(strings.TrimSpace(out), "\n")))
+	assert.Contains(t, out, "dev")
+
+	_, code = runStarctl(t, server, "namespace", "-org", "GITHUB/unknown", "-cluster", "default", "list")
+	assert.Equal(t, 1, code)
+}
+
+func countRequests(server *fake.Server, request string) int {
+	n := 0
+	for _, r := range server.Requests() {
+		if r == request {
+			n++
+		}
+	}
+	return n
+}
+
+func TestLookupCache(t *testing.T) {
+	server := newFakeServer(t)
+	args := []string{"namespace", "-org", "GITHUB/staroids", "-cluster", "default", "list"}
+
+	runStarctl(t, server, args...)
+	runStarctl(t, server, args...)
+	assert.Equal(t, 1, countRequests(server, "GET /orgs/"))
+
+	runStarctl(t, server, append([]string{"-no-cache"}, args...)...)
+	assert.Equal(t, 2, countRequests(server, "GET /orgs/"))
+
+	out, code := runStarctl(t, server, "cache", "clear")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "Cache cleared")
+	runStarctl(t, server, args...)
+	assert.Equal(t, 3, countRequests(server, "GET /orgs/"))
+}
+
+func TestClusterListPartialFailure(t *testing.T) {
+	server := newFakeServer(t)
+	broken := server.AddOrg("GITHUB", "broken")
+	server.AddCluster(broken, "other", "gcp", "us-central1")
+	server.InjectFault(fake.Fault{Path: "/orgs/GITHUB/broken/vc", Status: 500, Message: "boom"})
+
+	out, code := runStarctl(t, server, "cluster", "list")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "default  GITHUB/staroids  aws/us-west2")
+	assert.NotContains(t, out, "other")
+	assert.Contains(t, out, "Failed to list clusters of 1 org(s)")
+	assert.Contains(t, out, "GITHUB/broken  500 boom")
+
+	_, code = runStarctl(t, server, "cluster", "-strict", "list")
+	assert.Equal(t, 1, code)
+}
+
+func TestProfileDefaults(t *testing.T) {
+	server := newFakeServer(t)
+
+	_, code := runStarctl(t, server, "-profile", "dev", "config", "set", "org", "GITHUB/staroids")
+	assert.Equal(t, 0, code)
+	runStarctl(t, server, "-profile", "dev", "config", "set", "cluster", "default")
+
+	out, code := runStarctl(t, server, "-profile", "dev", "namespace", "list")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "ALIAS")
+
+	_, code = runStarctl(t, server, "namespace", "list")
+	assert.Equal(t, 1, code)
+
+	_, code = runStarctl(t, server, "-profile", "unknown", "namespace", "list")
+	assert.Equal(t, 1, code)
+}
+
+func TestLoginLogout(t *testing.T) {
+	server := newFakeServer(t)
+
+	// stand-in login page redirecting back with the token
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirect, _ := url.Parse(r.URL.Query().Get("redirect_uri"))
+		q := redirect.Query()
+		q.Set("state", r.URL.Query().Get("state"))
+		q.Set("token", server.Token())
+		redirect.RawQuery = q.Encode()
+		http.Redirect(w, r, redirect.String(), http.StatusFound)
+	}))
+	defer authServer.Close()
+
+	cmd := starctlCommand(server, "-profile", "dev", "login", "-no-browser", "-auth-url", authServer.URL)
+	stdout, _ := cmd.StdoutPipe()
+	assert.Nil(t, cmd.Start())
+
+	out := ""
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		line := scanner.Text()
+		out += line + "\n"
+		if strings.HasPrefix(line, "  "+authServer.URL) {
+			resp, err := http.Get(strings.TrimSpace(line))
+			assert.Nil(t, err)
+			resp.Body.Close()
+		}
+	}
+	assert.Nil(t, cmd.Wait())
+	assert.Contains(t, out, "Logged in to "+server.URL+". 1 org(s) accessible. Access token saved to profile 'dev'.")
+
+	credentials, err := ioutil.ReadFile(filepath.Join(os.Getenv(constants.EnvStarctlConfigDir), "credentials"))
+	assert.Nil(t, err)
+	assert.NotContains(t, string(credentials), server.Token())
+
+	out, code := runStarctl(t, server, "-profile", "dev", "auth", "list")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "dev")
+	assert.NotContains(t, out, server.Token())
+
+	out, code = runStarctl(t, server, "-profile", "dev", "logout")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "Access token removed from profile 'dev'.")
+	assert.Contains(t, out, "Warning: the access token is not revoked and is still valid.")
+
+	_, code = runStarctl(t, server, "-profile", "dev", "logout")
+	assert.Equal(t, 1, code)
+}
+
+func TestAuthAddRemove(t *testing.T) {
+	server := newFakeServer(t)
+
+	cmd := starctlCommand(server, "-profile", "dev", "auth", "add")
+	cmd.Stdin = strings.NewReader(server.Token() + "\n")
+	out, err := cmd.CombinedOutput()
+	assert.Nil(t, err)
+	assert.Contains(t, string(out), "Access token saved to profile 'dev'.")
+
+	// use the stored token instead of the environment variable
+	cmd = starctlCommand(server, "-profile", "dev", "cluster", "list")
+	cmd.Env = append(cmd.Env, constants.EnvStaroidAccessToken+"=")
+	out, err = cmd.CombinedOutput()
+	assert.Nil(t, err)
+	assert.Contains(t, string(out), "default")
+
+	removeOut, code := runStarctl(t, server, "-profile", "dev", "auth", "remove")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, removeOut, "Access token removed from profile 'dev'.")
+
+	_, code = runStarctl(t, server, "-profile", "dev", "auth", "remove")
+	assert.Equal(t, 1, code)
+}
+
+func TestWhoami(t *testing.T) {
+	server := newFakeServer(t)
+
+	out, code := runStarctl(t, server, "auth", "whoami")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "GITHUB/fake-user")
+	assert.Contains(t, out, "env "+constants.EnvStaroidAccessToken)
+	assert.Contains(t, out, "GITHUB/staroids")
+
+	out, code = runStarctl(t, server, "auth", "whoami", "-o", "json")
+	assert.Equal(t, 0, code)
+	whoami := Whoami{}
+	assert.Nil(t, json.Unmarshal([]byte(out), &whoami))
+	assert.Equal(t, "fake-user", whoami.User.Name)
+	assert.Equal(t, server.URL, whoami.ApiServer)
+	assert.Equal(t, 1, len(whoami.Orgs))
+
+	server.SetToken("rotated-token")
+	out, code = runStarctl(t, server, "auth", "whoami", "-o", "json")
+	assert.Equal(t, 1, code)
+	whoami = Whoami{}
+	assert.Nil(t, json.Unmarshal([]byte(out), &whoami))
+	assert.Contains(t, whoami.Error, "is not valid")
+
+	// not logged in
+	cmd := starctlCommand(server, "auth", "whoami", "-o", "json")
+	cmd.Env = append(cmd.Env, constants.EnvStaroidAccessToken+"=")
+	outBytes, _ := cmd.Output()
+	assert.Equal(t, 1, cmd.ProcessState.ExitCode())
+	whoami = Whoami{}
+	assert.Nil(t, json.Unmarshal(outBytes, &whoami))
+	assert.Contains(t, whoami.Error, "Please run 'starctl login'")
+	assert.Equal(t, server.URL, whoami.ApiServer)
+}
+
+func TestContexts(t *testing.T) {
+	server := newFakeServer(t)
+	org := server.AddOrg("GITHUB", "other")
+	cluster := server.AddCluster(org, "default", "aws", "us-west2")
+	server.AddNamespace(cluster, "my-ns", v1.NamespacePhaseRunning)
+
+	_, code := runStarctl(t, server, "context", "set", "dev", "-org", "GITHUB/other", "-cluster", "default", "-ns-alias", "my-ns")
+	assert.Equal(t, 0, code)
+	_, code = runStarctl(t, server, "context", "current")
+	assert.Equal(t, 1, code)
+
+	_, code = runStarctl(t, server, "context", "use", "dev")
+	assert.Equal(t, 0, code)
+	out, code := runStarctl(t, server, "context", "list")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "*        dev")
+
+	out, code = runStarctl(t, server, "config", "view")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "current-context: dev")
+	assert.Regexp(t, `contexts:\n  dev:\n    org: GITHUB/other\n    cluster: default\n    namespace: my-ns`, out)
+
+	out, code = runStarctl(t, server, "namespace", "stop")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "my-ns")
+
+	// flags override the context
+	out, code = runStarctl(t, server, "namespace", "-org", "GITHUB/staroids", "list")
+	assert.Equal(t, 0, code)
+	assert.NotContains(t, out, "my-ns")
+
+	_, code = runStarctl(t, server, "-context", "unknown", "namespace", "list")
+	assert.Equal(t, 1, code)
+}
+
+func TestProjectFile(t *testing.T) {
+	server := newFakeServer(t)
+
+	dir, err := ioutil.TempDir("", "starctl-project")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	sub := filepath.Join(dir, "src", "app")
+	assert.Nil(t, os.MkdirAll(sub, 0700))
+	projectFile := filepath.Join(dir, ".starctl.yaml")
+	ioutil.WriteFile(projectFile, []byte("org: GITHUB/staroids\ncluster: default\nns-alias: app\n"), 0644)
+
+	run := func(env []string, args ...string) (string, error) {
+		cmd := starctlCommand(server, args...)
+		cmd.Dir = sub
+		cmd.Env = append(cmd.Env, env...)
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+
+	out, err := run(nil, "namespace", "create")
+	assert.Nil(t, err)
+	assert.Contains(t, out, "app")
+
+	out, err = run([]string{constants.EnvStaroidCluster + "=other"}, "config", "explain", "-org", "GITHUB/someone")
+	assert.Nil(t, err)
+	assert.Regexp(t, `org +GITHUB/someone +flag`, out)
+	assert.Regexp(t, `cluster +other +env `+constants.EnvStaroidCluster, out)
+	assert.Regexp(t, `ns-alias +app +project file `+projectFile, out)
+	assert.Regexp(t, `project +GITHUB/staroids/namespace:master +default`, out)
+
+	// the project file wins over the current context, but not over a context asked for explicitly
+	_, err = run(nil, "context", "set", "dev", "-ns-alias", "ctx-ns")
+	assert.Nil(t, err)
+	_, err = run(nil, "context", "use", "dev")
+	assert.Nil(t, err)
+	out, err = run(nil, "config", "explain")
+	assert.Nil(t, err)
+	assert.Regexp(t, `ns-alias +app +project file `+projectFile, out)
+
+	out, err = run(nil, "-context", "dev", "config", "explain")
+	assert.Nil(t, err)
+	assert.Regexp(t, `context +dev +flag`, out)
+	assert.Regexp(t, `ns-alias +ctx-ns +context 'dev'`, out)
+	assert.Regexp(t, `org +GITHUB/staroids +project file `+projectFile, out)
+
+	out, err = run([]string{constants.EnvStarctlContext + "=dev"}, "config", "explain")
+	assert.Nil(t, err)
+	assert.Regexp(t, `context +dev +env `+constants.EnvStarctlContext, out)
+	assert.Regexp(t, `ns-alias +ctx-ns +context 'dev'`, out)
+}
+
+func TestClusterCreateGetDelete(t *testing.T) {
+	server := newFakeServer(t)
+
+	out, code := runStarctl(t, server, "cluster", "-org", "GITHUB/staroids", "-cloud", "gcp", "-region", "us-central1", "create", "new")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "gcp/us-central1")
+
+	_, code = runStarctl(t, server, "namespace", "-org", "GITHUB/staroids", "-cluster", "new", "create", "dev")
+	assert.Equal(t, 0, code)
+
+	out, code = runStarctl(t, server, "cluster", "-org", "GITHUB/staroids", "get", "new")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "Region:  us-central1")
+	assert.Contains(t, out, "Namespaces: 1")
+
+	// guard against deleting namespaces
+	out, code = runStarctl(t, server, "cluster", "-org", "GITHUB/staroids", "-yes", "delete", "new")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, out, "-cascade")
+
+	// confirmation
+	cmd := starctlCommand(server, "cluster", "-org", "GITHUB/staroids", "-cascade", "delete", "new")
+	cmd.Stdin = strings.NewReader("n\n")
+	_, err := cmd.CombinedOutput()
+	assert.NotNil(t, err)
+
+	out, code = runStarctl(t, server, "cluster", "-org", "GITHUB/staroids", "-cascade", "-yes", "-wait", "delete", "new")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "new deleted")
+
+	_, code = runStarctl(t, server, "cluster", "-org", "GITHUB/staroids", "get", "new")
+	assert.Equal(t, 1, code)
+}
+
+func TestClusterDeleteNamespaces(t *testing.T) {
+	server := newFakeServer(t)
+	server.SetManualPhase(true)
+	org := server.AddOrg("GITHUB", "other")
+
+	// namespaces already deleted are not guarded against
+	cluster := server.AddCluster(org, "old", "aws", "us-west2")
+	server.AddNamespace(cluster, "gone", v1.NamespacePhaseRemoved)
+	out, code := runStarctl(t, server, "cluster", "-org", "GITHUB/other", "-yes", "delete", "old")
+	assert.Equal(t, 0, code, out)
+	assert.Equal(t, 0, countRequests(server, fmt.Sprintf("DELETE /orgs/GITHUB/other/vc/%d/instance", cluster.ID)))
+
+	// all namespaces are tried and the failed ones are reported
+	cluster = server.AddCluster(org, "busy", "aws", "us-west2")
+	failing := server.AddNamespace(cluster, "failing", v1.NamespacePhaseRunning)
+	other := server.AddNamespace(cluster, "other", v1.NamespacePhaseRunning)
+	failingPath := fmt.Sprintf("/orgs/GITHUB/other/vc/%d/instance/%d", cluster.ID, failing.ID)
+	server.InjectFault(fake.Fault{Method: "DELETE", Path: failingPath, Status: 403, Message: "denied"})
+	out, code = runStarctl(t, server, "cluster", "-org", "GITHUB/other", "-cascade", "-yes", "delete", "busy")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, out, "Can't delete 1 of 2 namespace(s)")
+	assert.Contains(t, out, "failing: 403")
+	assert.Equal(t, 1, countRequests(server, fmt.Sprintf("DELETE /orgs/GITHUB/other/vc/%d/instance/%d", cluster.ID, other.ID)))
+	server.ClearFaults()
+
+	// namespaces share a single deadline
+	cluster = server.AddCluster(org, "slow", "aws", "us-west2")
+	for i := 0; i < 5; i++ {
+		server.AddNamespace(cluster, fmt.Sprintf("ns-%d", i), v1.NamespacePhaseRunning)
+	}
+	start := time.Now()
+	out, code = runStarctl(t, server, "cluster", "-org", "GITHUB/other", "-cascade", "-yes", "-timeout", "500ms", "-poll-interval", "50ms", "delete", "slow")
+	assert.Equal(t, ExitCodeTimeout, code)
+	assert.Contains(t, out, "Timed out after 500ms waiting for ns-0")
+	assert.Less(t, int64(time.Since(start)), int64(2*time.Second))
+}
+
+func TestSkeList(t *testing.T) {
+	server := newFakeServer(t)
+
+	out, code := runStarctl(t, server, "ske", "-available", "list")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "us-central1")
+	assert.NotContains(t, out, "eastus")
+
+	out, code = runStarctl(t, server, "cluster", "-org", "GITHUB/staroids", "-cloud", "aws", "-region", "us-east9", "create", "new")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, out, "SKE aws/us-east9 not found")
+	assert.Equal(t, 0, countRequests(server, "POST /orgs/GITHUB/staroids/vc"))
+}
+
+func TestOrgListGet(t *testing.T) {
+	server := newFakeServer(t)
+	org := server.AddOrg("GITHUB", "other")
+	cluster := server.AddCluster(org, "c1", "aws", "us-west2")
+	server.AddCluster(org, "c2", "aws", "us-west2")
+	server.AddNamespace(cluster, "a", v1.NamespacePhaseRunning)
+	server.AddNamespace(cluster, "b", v1.NamespacePhaseRunning)
+
+	out, code := runStarctl(t, server, "org", "list")
+	assert.Equal(t, 0, code)
+	assert.Regexp(t, `GITHUB/staroids +\d+ +GITHUB +1 +0`, out)
+	assert.Regexp(t, `GITHUB/other +\d+ +GITHUB +2 +2`, out)
+
+	out, code = runStarctl(t, server, "org", "get", "GITHUB/other")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "Namespaces:  2")
+	assert.Regexp(t, `c1 +aws/us-west2 +2`, out)
+
+	out, code = runStarctl(t, server, "org", "get", "GITHUB/staroid")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, out, "Did you mean GITHUB/staroids?")
+}
+
+func TestNamespaceDescribe(t *testing.T) {
+	server := newFakeServer(t)
+	args := []string{"namespace", "-org", "GITHUB/staroids", "-cluster", "default"}
+
+	_, code := runStarctl(t, server, append(args, "-project", "GITHUB/staroids/app:master#abc123", "create", "dev")...)
+	assert.Equal(t, 0, code)
+
+	out, code := runStarctl(t, server, append(args, "describe", "dev")...)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "Project:  GITHUB/staroids/app:master#abc123")
+	assert.Contains(t, out, "Access:   PRIVATE")
+	assert.Contains(t, out, "Shell:    not running")
+	assert.Contains(t, out, "Services: 0")
+
+	// services and endpoints
+	org := server.AddOrg("GITHUB", "other")
+	cluster := server.AddCluster(org, "web", "aws", "us-west2")
+	ns := server.AddNamespace(cluster, "app", v1.NamespacePhaseRunning)
+	server.AddService(ns.ID, corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "frontend"},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "http", Port: 8080}},
+		},
+	})
+	_, code = runStarctl(t, server, "shell", "-org", "GITHUB/other", "-cluster", "web", "start", "app")
+	assert.Equal(t, 0, code)
+
+	out, code = runStarctl(t, server, "namespace", "-org", "GITHUB/other", "-cluster", "web", "get", "app")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "Project:  -")
+	assert.Contains(t, out, "Shell:    running")
+	assert.Contains(t, out, "Services: 2")
+	assert.Regexp(t, `frontend +http +8080 +`+regexp.QuoteMeta(ns.ServiceURL("frontend", 8080)), out)
+}
+
+func TestNamespaceWatch(t *testing.T) {
+	server := newFakeServer(t)
+	args := []string{"namespace", "-org", "GITHUB/staroids", "-cluster", "default", "-poll-interval", "10ms"}
+
+	runStarctl(t, server, append(args, "create", "dev")...)
+	runStarctl(t, server, append(args, "create", "test")...)
+
+	// phase advances on every read. exits when both are running
+	out, code := runStarctl(t, server, append(args, "-until", "running", "watch", "dev", "test")...)
+	assert.Equal(t, 0, code)
+	assert.Regexp(t, `dev +STARTING +ACTIVE`, out)
+	assert.Regexp(t, `test +RUNNING +ACTIVE`, out)
+
+	// watching a namespace being deleted
+	listed := func() int {
+		n := 0
+		for _, r := range server.Requests() {
+			if strings.HasPrefix(r, "GET ") && strings.HasSuffix(r, "/instance") {
+				n++
+			}
+		}
+		return n
+	}
+	before := listed()
+	watchOut := &strings.Builder{}
+	watch := starctlCommand(server, append(args, "-until", "REMOVED", "watch", "dev")...)
+	watch.Stdout = watchOut
+	assert.Nil(t, watch.Start())
+	for listed() == before {
+		time.Sleep(time.Millisecond)
+	}
+	runStarctl(t, server, append(args, "delete", "dev")...)
+	assert.Nil(t, watch.Wait())
+	assert.Regexp(t, `dev +RUNNING +ACTIVE`, watchOut.String())
+	assert.Regexp(t, `dev +REMOVED +INACTIVE`, watchOut.String())
+
+	out, code = runStarctl(t, server, append(args, "-watch", "-until", "RUNNING", "list")...)
+	assert.Equal(t, 0, code)
+	assert.Regexp(t, `ALIAS +NAME +TYPE +PHASE`, out)
+	assert.Regexp(t, `test +instance-\d+ +DEV +RUNNING`, out)
+
+	_, code = runStarctl(t, server, append(args, "-until", "UP", "watch")...)
+	assert.Equal(t, 1, code)
+}
+
+func TestNamespaceBulk(t *testing.T) {
+	server := newFakeServer(t)
+	args := []string{"namespace", "-org", "GITHUB/staroids", "-cluster", "default"}
+	for _, alias := range []string{"dev", "pr-1", "pr-2", "pr-3"} {
+		_, code := runStarctl(t, server, append(args, "create", alias)...)
+		assert.Equal(t, 0, code)
+	}
+
+	out, code := runStarctl(t, server, append(args, "-dry-run", "stop", "pr-*")...)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "Would stop 3 namespace(s)")
+	assert.Equal(t, 0, countRequests(server, "PUT /orgs/"))
+
+	out, code = runStarctl(t, server, append(args, "-wait", "stop", "pr-*", "dev")...)
+	assert.Equal(t, 0, code)
+	for _, alias := range []string{"dev", "pr-1", "pr-2", "pr-3"} {
+		assert.Regexp(t, alias+` +instance-\d+ +PAUSED +stopped`, out)
+	}
+
+	// deleting multiple namespaces asks for confirmation
+	cmd := starctlCommand(server, append(args, "delete", "pr-1", "pr-2")...)
+	cmd.Stdin = strings.NewReader("n\n")
+	outBytes, _ := cmd.CombinedOutput()
+	assert.Contains(t, string(outBytes), "Delete 2 namespace(s) above? [y/N] Canceled")
+	assert.Equal(t, 1, cmd.ProcessState.ExitCode())
+
+	out, code = runStarctl(t, server, append(args, "-all", "-phase", "PAUSED", "-yes", "delete")...)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, 4, strings.Count(out, "deleted"))
+
+	out, code = runStarctl(t, server, append(args, "start", "pr-*")...)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "No namespace matched")
+
+	// selecting namespaces is only for start, stop and delete
+	posts := countRequests(server, "POST /orgs/")
+	for _, extra := range [][]string{{"-dry-run", "create", "new"}, {"create", "a", "b"}, {"get", "pr-*"}, {"-all", "watch"}} {
+		out, code = runStarctl(t, server, append(args, extra...)...)
+		assert.Equal(t, 2, code, extra)
+		assert.Contains(t, out, "namespace [flags]", extra)
+	}
+	assert.Equal(t, posts, countRequests(server, "POST /orgs/"))
+}
+
+func TestNamespaceWaitExitCodes(t *testing.T) {
+	server := newFakeServer(t)
+	server.SetManualPhase(true)
+	org := server.AddOrg("GITHUB", "other")
+	cluster := server.AddCluster(org, "c1", "aws", "us-west2")
+	args := []string{"-no-cache", "namespace", "-org", "GITHUB/other", "-cluster", "c1", "-wait", "-timeout", "50ms", "-poll-interval", "5ms"}
+
+	running := server.AddNamespace(cluster, "running", v1.NamespacePhaseRunning)
+	out, code := runStarctl(t, server, append(args, "start", "running")...)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "running reached RUNNING phase")
+
+	server.AddNamespace(cluster, "paused", v1.NamespacePhasePaused)
+	out, code = runStarctl(t, server, append(args, "start", "paused")...)
+	assert.Equal(t, ExitCodeTimeout, code)
+	assert.Contains(t, out, "Timed out after 50ms waiting for paused. Last phase: PAUSED")
+
+	// removed while starting
+	failed := server.AddNamespace(cluster, "failed", v1.NamespacePhaseStarting)
+	polls := func() int {
+		return countRequests(server, fmt.Sprintf("GET /orgs/GITHUB/other/vc/%d/instance/%d", cluster.ID, failed.ID))
+	}
+	before := polls()
+	start := starctlCommand(server, append(args, "-timeout", "10s", "start", "failed")...)
+	startOut := &strings.Builder{}
+	start.Stdout = startOut
+	assert.Nil(t, start.Start())
+	for polls() == before {
+		time.Sleep(time.Millisecond)
+	}
+	server.SetPhase(failed.ID, v1.NamespacePhaseRemoved)
+	start.Wait()
+	assert.Equal(t, ExitCodeFailedPhase, start.ProcessState.ExitCode())
+	assert.Contains(t, startOut.String(), "failed is in REMOVED phase")
+
+	// bulk operation exits with the code of the first failure
+	_, code = runStarctl(t, server, append(args, "start", "running", "paused")...)
+	assert.Equal(t, ExitCodeTimeout, code)
+
+	server.InjectFault(fake.Fault{Method: "GET", Path: fmt.Sprintf("/orgs/GITHUB/other/vc/%d/instance/%d", cluster.ID, running.ID), Status: 404})
+	out, code = runStarctl(t, server, append(args, "stop", "running")...)
+	assert.Equal(t, ExitCodeAPIError, code)
+	assert.Contains(t, out, "Can't get status of running")
+	server.ClearFaults()
+
+	// server goes away while waiting
+	gone := server.AddNamespace(cluster, "gone", v1.NamespacePhaseStarting)
+	polls = func() int {
+		return countRequests(server, fmt.Sprintf("GET /orgs/GITHUB/other/vc/%d/instance/%d", cluster.ID, gone.ID))
+	}
+	before = polls()
+	start = starctlCommand(server, append(append([]string{"-max-attempts", "1"}, args...), "-timeout", "10s", "start", "gone")...)
+	startOut = &strings.Builder{}
+	start.Stdout = startOut
+	assert.Nil(t, start.Start())
+	for polls() == before {
+		time.Sleep(time.Millisecond)
+	}
+	server.Close()
+	start.Wait()
+	assert.Equal(t, ExitCodeAPIError, start.ProcessState.ExitCode())
+	assert.Contains(t, startOut.String(), "Can't get status of gone")
+}
+
+func TestNamespaceCreateFromSpec(t *testing.T) {
+	server := newFakeServer(t)
+	org := server.AddOrg("GITHUB", "other")
+	server.AddCluster(org, "c1", "aws", "us-west2")
+
+	dir, _ := ioutil.TempDir("", "starctl-spec")
+	defer os.RemoveAll(dir)
+	spec := filepath.Join(dir, "spec.yaml")
+	ioutil.WriteFile(spec, []byte(`
+alias: dev
+project: {provider: GITHUB, owner: staroids, repo: app, branch: master, commit: abc123}
+---
+alias: pr-1
+org: GITHUB/other
+cluster: c1
+project: {provider: GITHUB, owner: staroids, repo: app, branch: pr-1}
+state: paused
+`), 0644)
+
+	out, code := runStarctl(t, server, "namespace", "-org", "GITHUB/staroids", "-cluster", "default", "-wait", "-poll-interval", "5ms", "-f", spec, "create")
+	assert.Equal(t, 0, code)
+	assert.Regexp(t, `dev +instance-\d+ +GITHUB/staroids +default +RUNNING`, out)
+	assert.Regexp(t, `pr-1 +instance-\d+ +GITHUB/other +c1 +PAUSED`, out)
+
+	out, code = runStarctl(t, server, "namespace", "-org", "GITHUB/staroids", "-cluster", "default", "describe", "dev")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "Project:  GITHUB/staroids/app:master#abc123")
+
+	// invalid spec creates nothing
+	ioutil.WriteFile(spec, []byte("alias: test\nproject: {provider: GITHUB, owner: staroids, repo: app}\n"), 0644)
+	out, code = runStarctl(t, server, "namespace", "-org", "GITHUB/staroids", "-f", spec, "create")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, out, spec+": cluster: is required")
+	assert.Contains(t, out, spec+": project.branch: is required")
+	created := 0
+	for _, r := range server.Requests() {
+		if strings.HasPrefix(r, "POST ") && strings.HasSuffix(r, "/instance") {
+			created++
+		}
+	}
+	assert.Equal(t, 2, created)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// levenshtein returns edit distance between a and b
+func levenshtein(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// Suggest returns candidates similar to name, most similar first.
+// Comparison is case insensitive, and a candidate whose part after '/' equals name matches too.
+func Suggest(name string, candidates []string) []string {
+	type scored struct {
+		candidate string
+		distance  int
+	}
+
+	lower := strings.ToLower(name)
+	maxDistance := len(lower) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	matches := make([]scored, 0)
+	for _, c := range candidates {
+		lc := strings.ToLower(c)
+		distance := levenshtein(lower, lc)
+		if i := strings.LastIndex(lc, "/"); i >= 0 && !strings.Contains(lower, "/") {
+			if d := levenshtein(lower, lc[i+1:]); d < distance {
+				distance = d
+			}
+		}
+		if distance <= maxDistance {
+			matches = append(matches, scored{c, distance})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].distance < matches[j].distance
+	})
+	suggestions := make([]string, len(matches))
+	for i, m := range matches {
+		suggestions[i] = m.candidate
+	}
+	return suggestions
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"GITHUB/staroids", "GITHUB/open-datastudio", "GITLAB/staroids-dev"}
+
+	tests := []struct {
+		name     string
+		expected []string
+	}{
+		{"GITHUB/staroids", []string{"GITHUB/staroids"}},
+		{"github/staroid", []string{"GITHUB/staroids"}},
+		{"staroids", []string{"GITHUB/staroids"}},
+		{"GITHUB/open-datastudo", []string{"GITHUB/open-datastudio"}},
+		{"GITLAB/staroids", []string{"GITHUB/staroids", "GITLAB/staroids-dev"}},
+		{"something-else", []string{}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Suggest(tt.name, candidates), tt.name)
+	}
+}
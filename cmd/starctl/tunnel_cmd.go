@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"net/http"
 	"os"
 
 	chclient "github.com/jpillora/chisel/client"
+	"github.com/staroids/starctl/pkg/config"
 	"github.com/staroids/starctl/pkg/constants"
 )
 
@@ -15,19 +17,14 @@ func TunnelCmdUsage(flagSet *flag.FlagSet) {
 	flagSet.Usage()
 }
 
-func TunnelCmd(args []string) {
+func TunnelCmd(ctx context.Context, args []string) {
 	tunnelCmdFlag := flag.NewFlagSet("tunnel", flag.ExitOnError)
-	orgName := tunnelCmdFlag.String("org", "", "organization (e.g. GITHUB/staroid)")
-	clusterName := tunnelCmdFlag.String("cluster", "", "name of cluster")
-	nsAlias := tunnelCmdFlag.String("ns-alias", "", "namespace alias")
+	orgName := tunnelCmdFlag.String("org", settingDefault("org"), "organization (e.g. GITHUB/staroid)")
+	clusterName := tunnelCmdFlag.String("cluster", settingDefault("cluster"), "name of cluster")
+	nsAlias := tunnelCmdFlag.String("ns-alias", settingDefault("ns-alias"), "namespace alias")
 	kubeProxy := tunnelCmdFlag.Bool("kube-proxy", false, "Kubernetes API proxy")
 	kubeProxyPort := tunnelCmdFlag.Int("kube-proxy-port", 8001, "Local port for Kubernetes API proxy")
 
-	if len(args) < 1 {
-		TunnelCmdUsage(tunnelCmdFlag)
-		os.Exit(1)
-	}
-
 	// check required flags
 	tunnelCmdFlag.Parse(args)
 
@@ -47,37 +44,40 @@ func TunnelCmd(args []string) {
 	}
 
 	remotes := tunnelCmdFlag.Args()
+	if len(remotes) == 0 && activeProject != nil {
+		remotes = append(remotes, activeProject.TunnelRemotes...)
+	}
 	if *kubeProxy {
 		remotes = append(remotes, fmt.Sprintf("%d:localhost:%d", *kubeProxyPort, 57683))
 	}
 
 	if len(remotes) == 0 {
-		fmt.Println("Set at least one [remote] argument or set '-kube-proxy' flag")
+		fmt.Printf("Set at least one [remote] argument, '-kube-proxy' flag or tunnel-remotes in %s\n", config.ProjectFileName)
 		os.Exit(1)
 	}
 
 	// valid value
 	staroidClient := CreateClient()
 
-	org, err := GetOrgFromName(staroidClient, *orgName)
+	org, err := GetOrgFromName(ctx, staroidClient, *orgName)
 	if err != nil {
 		fmt.Printf("%v\n", err)
 		os.Exit(1)
 	}
 
-	cluster, err := GetClusterFromName(staroidClient, org, *clusterName)
+	cluster, err := GetClusterFromName(ctx, staroidClient, org, *clusterName)
 	if err != nil {
 		fmt.Printf("%v\n", err)
 		os.Exit(1)
 	}
 
-	namespace, err := GetNamespaceFromAlias(staroidClient, org, cluster, *nsAlias)
+	namespace, err := GetNamespaceFromAlias(ctx, staroidClient, org, cluster, *nsAlias)
 	if err != nil {
 		fmt.Printf("%v\n", err)
 		os.Exit(1)
 	}
 
-	shellService, err := staroidClient.V1().Namespace().WithName(namespace.Namespace).GetShellService()
+	shellService, err := staroidClient.V1().Namespace().WithContext(ctx).WithName(namespace.Namespace).GetShellService()
 	if err != nil {
 		fmt.Printf("%v\n", err)
 		os.Exit(1)
@@ -98,7 +98,12 @@ func TunnelCmd(args []string) {
 		Headers:          http.Header{},
 		Remotes:          remotes,
 	}
-	chConfig.Headers.Set("Authorization", fmt.Sprintf("token %s", staroidClient.Auth.AccessToken()))
+	token, err := staroidClient.Auth.GetAccessToken()
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(1)
+	}
+	chConfig.Headers.Set("Authorization", fmt.Sprintf("token %s", token))
 	chClient, err := chclient.NewClient(&chConfig)
 	if err != nil {
 		fmt.Printf("%v\n", err)
@@ -112,7 +117,22 @@ func TunnelCmd(args []string) {
 		fmt.Printf("--------------------\n")
 	}
 
-	err = chClient.Run()
+	err = chClient.Start(ctx)
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(1)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- chClient.Wait()
+	}()
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		chClient.Close()
+	}
 	if err != nil {
 		fmt.Printf("%v\n", err)
 		os.Exit(1)
@@ -6,5 +6,8 @@ require (
 	github.com/briandowns/spinner v1.11.1
 	github.com/jpillora/chisel v1.6.0
 	github.com/stretchr/testify v1.4.0
+	golang.org/x/crypto v0.0.0-20200510223506-06a226fb4e37
+	gopkg.in/yaml.v2 v2.2.8
 	k8s.io/api v0.18.5
+	k8s.io/apimachinery v0.18.5
 )
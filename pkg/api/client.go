@@ -1,6 +1,13 @@
 package api
 
 import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/staroids/starctl/pkg/api/transport"
 	v1 "github.com/staroids/starctl/pkg/api/v1"
 	"github.com/staroids/starctl/pkg/auth"
 )
@@ -8,10 +15,64 @@ import (
 // StaroidClient is rest api client for staroid.com
 type StaroidClient struct {
 	Auth auth.StaroidAuth
+
+	// Retry configures retry of idempotent requests. Zero value uses transport.DefaultRetryPolicy()
+	Retry transport.RetryPolicy
+
+	// Timeout limits each api request, including retries. 0 means no timeout
+	Timeout time.Duration
+
+	// TLSConfig is used for connections to the api server. See transport.LoadTLSConfig()
+	TLSConfig *tls.Config
+
+	// Transport replaces the default base transport. TLSConfig is ignored when set
+	Transport http.RoundTripper
+
+	// Verbosity logs each request attempt to TraceOutput (os.Stderr when nil). See transport.TraceTransport
+	Verbosity   int
+	TraceOutput io.Writer
+	// TraceCurl prints curl command equivalent to each request
+	TraceCurl bool
+
+	once       sync.Once
+	httpClient *http.Client
+}
+
+// NewStaroidClient returns client reading access token from tokenSource.
+// Use auth.StaticTokenSource to supply a token programmatically
+func NewStaroidClient(tokenSource auth.TokenSource) *StaroidClient {
+	return &StaroidClient{
+		Auth: auth.StaroidAuth{TokenSource: tokenSource},
+	}
+}
+
+// HttpClient returns http client shared by all requests of this StaroidClient.
+// The client is built on first call; changing fields afterwards has no effect.
+func (c *StaroidClient) HttpClient() *http.Client {
+	c.once.Do(func() {
+		base := c.Transport
+		if base == nil {
+			defaultTransport := http.DefaultTransport.(*http.Transport).Clone()
+			if c.TLSConfig != nil {
+				defaultTransport.TLSClientConfig = c.TLSConfig
+			}
+			base = defaultTransport
+		}
+		if c.Verbosity > 0 || c.TraceCurl {
+			base = transport.NewTraceTransport(base, c.TraceOutput, c.Verbosity, c.TraceCurl)
+		}
+
+		c.httpClient = &http.Client{
+			Timeout:   c.Timeout,
+			Transport: transport.NewRetryTransport(base, c.Retry),
+		}
+	})
+	return c.httpClient
 }
 
 func (c *StaroidClient) V1() *v1.V1 {
 	return &v1.V1{
-		Auth: c.Auth,
+		Auth:   c.Auth,
+		Client: c.HttpClient(),
 	}
 }
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/staroids/starctl/pkg/api/transport"
+	"github.com/staroids/starctl/pkg/auth"
+	"github.com/staroids/starctl/pkg/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingTransport struct {
+	calls int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.calls++
+	return &http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(strings.NewReader("[]")),
+		Request:    req,
+	}, nil
+}
+
+func TestSharedHttpClient(t *testing.T) {
+	rt := &countingTransport{}
+	client := &StaroidClient{Transport: rt}
+
+	assert.True(t, client.V1().HttpClient() == client.V1().HttpClient())
+
+	orgs, err := client.V1().Org().GetAll()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(*orgs))
+	assert.Equal(t, 1, rt.calls)
+}
+
+func TestLoadTLSConfig(t *testing.T) {
+	config, err := transport.LoadTLSConfig("", "", "")
+	assert.Nil(t, err)
+	assert.Nil(t, config.RootCAs)
+
+	_, err = transport.LoadTLSConfig("", "client.pem", "")
+	assert.NotNil(t, err)
+
+	_, err = transport.LoadTLSConfig("/nonexistent/ca.pem", "", "")
+	assert.NotNil(t, err)
+}
+
+type headerTransport struct {
+	countingTransport
+	authorization string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.authorization = req.Header.Get("Authorization")
+	return t.countingTransport.RoundTrip(req)
+}
+
+func TestNewStaroidClient(t *testing.T) {
+	os.Setenv(constants.EnvStaroidAccessToken, "env-token")
+	defer os.Unsetenv(constants.EnvStaroidAccessToken)
+
+	rt := &headerTransport{}
+	client := NewStaroidClient(auth.StaticTokenSource("static-token"))
+	client.Transport = rt
+
+	_, err := client.V1().Org().GetAll()
+	assert.Nil(t, err)
+	assert.Equal(t, "token static-token", rt.authorization)
+	assert.Equal(t, "token", client.Auth.Source())
+}
@@ -0,0 +1,626 @@
+// Package fake provides an in-process fake of the staroid.com rest api for tests.
+//
+//	server := fake.NewServer()
+//	defer server.Close()
+//	os.Setenv("STAROID_API_SERVER", server.URL)
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	v1 "github.com/staroids/starctl/pkg/api/v1"
+	"github.com/staroids/starctl/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Fault makes matching requests fail with Status
+type Fault struct {
+	// Method to match. empty matches any method
+	Method string
+	// Path prefix to match. empty matches any path
+	Path    string
+	Status  int
+	Message string
+	Header  http.Header
+	// Times is number of requests to fail. <= 0 fails until ClearFaults() is called
+	Times int
+}
+
+// Namespace is the server side state of a namespace
+type Namespace struct {
+	v1.StaroidNamespace
+	ClusterID int64
+	Services  []corev1.Service
+}
+
+// Server is a fake staroid api server.
+// Namespace phase advances one step (SCHEDULED -> STARTING -> RUNNING, RUNNING -> PAUSED, ... -> REMOVED)
+// every time the namespace is read, unless SetManualPhase(true) is called.
+type Server struct {
+	*httptest.Server
+
+	// User is returned by /user
+	User v1.StaroidUser
+
+	mu sync.Mutex
+	// token is the access token required in Authorization header. empty accepts any token
+	token string
+	// manualPhase disables automatic phase transitions
+	manualPhase bool
+	nextID      int64
+	orgs        []v1.StaroidOrg
+	skes        []v1.StaroidSke
+	clusters    []v1.StaroidCluster
+	namespaces  []*Namespace
+	faults      []*Fault
+	requests    []string
+}
+
+// NewServer starts a fake api server. Call Close() when finished.
+func NewServer() *Server {
+	s := &Server{
+		nextID: 100,
+		User: v1.StaroidUser{
+			ID:       1,
+			Provider: "GITHUB",
+			Name:     "fake-user",
+			Email:    "fake-user@example.com",
+		},
+	}
+	s.AddSke("aws", "us-west2", true)
+	s.AddSke("gcp", "us-central1", true)
+	s.AddSke("azure", "eastus", false)
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+func (s *Server) newID() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+// AddOrg adds an organization
+func (s *Server) AddOrg(provider string, name string) v1.StaroidOrg {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	org := v1.StaroidOrg{
+		ID:       s.newID(),
+		Provider: provider,
+		Name:     name,
+	}
+	s.orgs = append(s.orgs, org)
+	return org
+}
+
+// AddSke adds a SKE. aws/us-west2, gcp/us-central1 and unavailable azure/eastus exist from the start
+func (s *Server) AddSke(cloud string, region string, available bool) v1.StaroidSke {
+	return s.addSke(cloud, region, &available)
+}
+
+// AddSkeWithoutAvailability adds a SKE listed without 'available' key
+func (s *Server) AddSkeWithoutAvailability(cloud string, region string) v1.StaroidSke {
+	return s.addSke(cloud, region, nil)
+}
+
+func (s *Server) addSke(cloud string, region string, available *bool) v1.StaroidSke {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ske := v1.StaroidSke{
+		ID:        fmt.Sprintf("%s %s", cloud, region),
+		Cloud:     cloud,
+		Region:    region,
+		Available: available,
+	}
+	s.skes = append(s.skes, ske)
+	return ske
+}
+
+// AddCluster adds a cluster to the org
+func (s *Server) AddCluster(org v1.StaroidOrg, name string, cloud string, region string) v1.StaroidCluster {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addCluster(&org, name, cloud, region)
+}
+
+func (s *Server) addCluster(org *v1.StaroidOrg, name string, cloud string, region string) v1.StaroidCluster {
+	cluster := v1.StaroidCluster{
+		ID:    s.newID(),
+		Name:  name,
+		OrgID: org.ID,
+		Type:  "SKE",
+		Ske: v1.StaroidSke{
+			ID:     fmt.Sprintf("%s %s", cloud, region),
+			Cloud:  cloud,
+			Region: region,
+		},
+	}
+	s.clusters = append(s.clusters, cluster)
+	return cluster
+}
+
+// AddNamespace adds a namespace to the cluster in the given phase
+func (s *Server) AddNamespace(cluster v1.StaroidCluster, alias string, phase v1.NamespacePhase) v1.StaroidNamespace {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addNamespace(cluster.ID, alias, phase, nil).StaroidNamespace
+}
+
+func (s *Server) addNamespace(clusterID int64, alias string, phase v1.NamespacePhase, commit *v1.Commit) *Namespace {
+	id := s.newID()
+	name := fmt.Sprintf("instance-%d", id)
+	status := v1.NamespaceStatusActive
+	switch phase {
+	case v1.NamespacePhasePaused:
+		status = v1.NamespaceStatusPause
+	case v1.NamespacePhaseRemoved:
+		status = v1.NamespaceStatusInactive
+	}
+
+	ns := &Namespace{
+		StaroidNamespace: v1.StaroidNamespace{
+			ID:        id,
+			Namespace: name,
+			Alias:     alias,
+			Type:      "DEV",
+			Phase:     phase,
+			Status:    status,
+			Access:    "PRIVATE",
+			URL:       fmt.Sprintf("https://%s.fake.staroid.com", name),
+			Commit:    commit,
+		},
+		ClusterID: clusterID,
+	}
+	s.namespaces = append(s.namespaces, ns)
+	return ns
+}
+
+// AddService adds a kubernetes service to the namespace
+func (s *Server) AddService(namespaceID int64, service corev1.Service) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ns := s.findNamespace(namespaceID); ns != nil {
+		ns.Services = append(ns.Services, service)
+	}
+}
+
+// Namespace returns a copy of the namespace state, or nil if not exists
+func (s *Server) Namespace(namespaceID int64) *Namespace {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ns := s.findNamespace(namespaceID)
+	if ns == nil {
+		return nil
+	}
+	copied := *ns
+	return &copied
+}
+
+// SetToken sets the access token required in Authorization header. empty accepts any token
+func (s *Server) SetToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.token = token
+}
+
+// Token returns the access token required in Authorization header
+func (s *Server) Token() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.token
+}
+
+// SetManualPhase disables automatic phase transitions when manual is true. Use Advance() or SetPhase() instead
+func (s *Server) SetManualPhase(manual bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.manualPhase = manual
+}
+
+// SetPhase sets phase of the namespace
+func (s *Server) SetPhase(namespaceID int64, phase v1.NamespacePhase) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ns := s.findNamespace(namespaceID); ns != nil {
+		ns.Phase = phase
+	}
+}
+
+// Advance moves the namespace one step toward the phase its status asks for
+func (s *Server) Advance(namespaceID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ns := s.findNamespace(namespaceID); ns != nil {
+		advance(ns)
+	}
+}
+
+// InjectFault makes matching requests fail
+func (s *Server) InjectFault(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f := fault
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all injected faults
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+// Requests returns received requests in "METHOD path" format
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.requests...)
+}
+
+func advance(ns *Namespace) {
+	switch ns.Status {
+	case v1.NamespaceStatusActive:
+		switch ns.Phase {
+		case v1.NamespacePhaseScheduled:
+			ns.Phase = v1.NamespacePhaseStarting
+		case v1.NamespacePhaseStarting:
+			ns.Phase = v1.NamespacePhaseRunning
+		case v1.NamespacePhasePaused:
+			ns.Phase = v1.NamespacePhaseStarting
+		}
+	case v1.NamespaceStatusPause:
+		ns.Phase = v1.NamespacePhasePaused
+		ns.Services = removeShellService(ns.Services)
+	case v1.NamespaceStatusInactive:
+		ns.Phase = v1.NamespacePhaseRemoved
+		ns.Services = nil
+	}
+}
+
+func (s *Server) findSke(cloud string, region string) *v1.StaroidSke {
+	for i := range s.skes {
+		if s.skes[i].Cloud == cloud && s.skes[i].Region == region {
+			return &s.skes[i]
+		}
+	}
+	return nil
+}
+
+func (s *Server) removeCluster(clusterID int64) {
+	for i := range s.clusters {
+		if s.clusters[i].ID == clusterID {
+			s.clusters = append(s.clusters[:i], s.clusters[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *Server) findNamespace(namespaceID int64) *Namespace {
+	for _, ns := range s.namespaces {
+		if ns.ID == namespaceID {
+			return ns
+		}
+	}
+	return nil
+}
+
+func (s *Server) findOrg(provider string, name string) *v1.StaroidOrg {
+	for i := range s.orgs {
+		if s.orgs[i].Provider == provider && s.orgs[i].Name == name {
+			return &s.orgs[i]
+		}
+	}
+	return nil
+}
+
+func (s *Server) findCluster(org *v1.StaroidOrg, clusterID int64) *v1.StaroidCluster {
+	for i := range s.clusters {
+		if s.clusters[i].ID == clusterID && s.clusters[i].OrgID == org.ID {
+			return &s.clusters[i]
+		}
+	}
+	return nil
+}
+
+func (s *Server) read(ns *Namespace) v1.StaroidNamespace {
+	if !s.manualPhase {
+		advance(ns)
+	}
+	return ns.StaroidNamespace
+}
+
+func (s *Server) matchFault(r *http.Request) *Fault {
+	for i, f := range s.faults {
+		if f.Method != "" && f.Method != r.Method {
+			continue
+		}
+		if !strings.HasPrefix(r.URL.Path, f.Path) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, fmt.Sprintf("%s %s", r.Method, r.URL.Path))
+
+	if s.token != "" && r.Header.Get("Authorization") != fmt.Sprintf("token %s", s.token) {
+		writeError(w, http.StatusUnauthorized, "invalid access token")
+		return
+	}
+
+	if f := s.matchFault(r); f != nil {
+		for k, values := range f.Header {
+			for _, v := range values {
+				w.Header().Add(k, v)
+			}
+		}
+		message := f.Message
+		if message == "" {
+			message = "injected fault"
+		}
+		writeError(w, f.Status, message)
+		return
+	}
+
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(path) == 1 && path[0] == "ske" && r.Method == "GET":
+		writeJSON(w, s.skes)
+	case len(path) == 1 && path[0] == "user" && r.Method == "GET":
+		writeJSON(w, s.User)
+	case len(path) == 1 && path[0] == "orgs" && r.Method == "GET":
+		writeJSON(w, s.orgs)
+	case len(path) >= 3 && path[0] == "orgs":
+		s.serveOrg(w, r, path[1:])
+	case len(path) == 2 && path[0] == "namespace" && r.Method == "GET":
+		s.serveResources(w, path[1])
+	default:
+		writeError(w, http.StatusNotFound, "no such api")
+	}
+}
+
+// serveOrg serves /orgs/{provider}/{org}/vc/...
+func (s *Server) serveOrg(w http.ResponseWriter, r *http.Request, path []string) {
+	org := s.findOrg(path[0], path[1])
+	if org == nil || path[2] != "vc" {
+		writeError(w, http.StatusNotFound, "org not found")
+		return
+	}
+	path = path[3:]
+
+	if len(path) == 0 {
+		switch r.Method {
+		case "GET":
+			clusters := make([]v1.StaroidCluster, 0)
+			for _, c := range s.clusters {
+				if c.OrgID == org.ID {
+					clusters = append(clusters, c)
+				}
+			}
+			writeJSON(w, clusters)
+		case "POST":
+			req := v1.ClusterCreateRequestMessage{}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" || req.Ske.Cloud == "" || req.Ske.Region == "" {
+				writeError(w, http.StatusBadRequest, "invalid request")
+				return
+			}
+			if ske := s.findSke(req.Ske.Cloud, req.Ske.Region); ske == nil || !ske.IsAvailable() {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("ske %s %s is not available", req.Ske.Cloud, req.Ske.Region))
+				return
+			}
+			for _, c := range s.clusters {
+				if c.OrgID == org.ID && c.Name == req.Name {
+					writeError(w, http.StatusConflict, fmt.Sprintf("cluster %s already exists", req.Name))
+					return
+				}
+			}
+			writeJSON(w, s.addCluster(org, req.Name, req.Ske.Cloud, req.Ske.Region))
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	clusterID, err := strconv.ParseInt(path[0], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid cluster id")
+		return
+	}
+	cluster := s.findCluster(org, clusterID)
+	if cluster == nil {
+		writeError(w, http.StatusNotFound, "cluster not found")
+		return
+	}
+	path = path[1:]
+
+	if len(path) == 0 {
+		switch r.Method {
+		case "GET":
+			writeJSON(w, cluster)
+		case "DELETE":
+			for _, ns := range s.namespaces {
+				if ns.ClusterID == cluster.ID && ns.Phase != v1.NamespacePhaseRemoved {
+					writeError(w, http.StatusConflict, "cluster has namespaces")
+					return
+				}
+			}
+			s.removeCluster(cluster.ID)
+			writeJSON(w, map[string]string{})
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	if path[0] != "instance" {
+		writeError(w, http.StatusNotFound, "no such api")
+		return
+	}
+	s.serveInstance(w, r, cluster, path[1:])
+}
+
+// serveInstance serves /orgs/{provider}/{org}/vc/{clusterID}/instance/...
+func (s *Server) serveInstance(w http.ResponseWriter, r *http.Request, cluster *v1.StaroidCluster, path []string) {
+	if len(path) == 0 {
+		switch r.Method {
+		case "GET":
+			namespaces := make([]v1.StaroidNamespace, 0)
+			for _, ns := range s.namespaces {
+				if ns.ClusterID == cluster.ID && ns.Phase != v1.NamespacePhaseRemoved {
+					namespaces = append(namespaces, s.read(ns))
+				}
+			}
+			writeJSON(w, namespaces)
+		case "POST":
+			req := v1.NamespaceStartRequestMessage{}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.InstanceName == "" {
+				writeError(w, http.StatusBadRequest, "invalid request")
+				return
+			}
+			for _, ns := range s.namespaces {
+				if ns.ClusterID == cluster.ID && ns.Alias == req.InstanceName && ns.Phase != v1.NamespacePhaseRemoved {
+					writeError(w, http.StatusConflict, fmt.Sprintf("instance %s already exists", req.InstanceName))
+					return
+				}
+			}
+			ns := s.addNamespace(cluster.ID, req.InstanceName, v1.NamespacePhaseScheduled, &req.Commit)
+			writeJSON(w, ns.StaroidNamespace)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	namespaceID, err := strconv.ParseInt(path[0], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid instance id")
+		return
+	}
+	ns := s.findNamespace(namespaceID)
+	if ns == nil || ns.ClusterID != cluster.ID {
+		writeError(w, http.StatusNotFound, "instance not found")
+		return
+	}
+
+	op := ""
+	if len(path) > 1 {
+		op = path[1]
+	}
+
+	switch {
+	case op == "" && r.Method == "GET":
+		writeJSON(w, s.read(ns))
+	case op == "" && r.Method == "DELETE":
+		ns.Status = v1.NamespaceStatusInactive
+		writeJSON(w, ns.StaroidNamespace)
+	case op == "pause" && r.Method == "PUT":
+		if ns.Status == v1.NamespaceStatusInactive {
+			writeError(w, http.StatusBadRequest, "instance removed")
+			return
+		}
+		ns.Status = v1.NamespaceStatusPause
+		writeJSON(w, ns.StaroidNamespace)
+	case op == "resume" && r.Method == "PUT":
+		if ns.Status == v1.NamespaceStatusInactive {
+			writeError(w, http.StatusBadRequest, "instance removed")
+			return
+		}
+		ns.Status = v1.NamespaceStatusActive
+		writeJSON(w, ns.StaroidNamespace)
+	case op == "shell" && r.Method == "POST":
+		if ns.Phase != v1.NamespacePhaseRunning {
+			writeError(w, http.StatusBadRequest, "instance is not running")
+			return
+		}
+		ns.Services = append(removeShellService(ns.Services), newShellService(ns.Namespace))
+		writeJSON(w, map[string]string{})
+	case op == "shell" && r.Method == "DELETE":
+		ns.Services = removeShellService(ns.Services)
+		writeJSON(w, map[string]string{})
+	default:
+		writeError(w, http.StatusNotFound, "no such api")
+	}
+}
+
+// serveResources serves /namespace/{name}
+func (s *Server) serveResources(w http.ResponseWriter, name string) {
+	for _, ns := range s.namespaces {
+		if ns.Namespace == name {
+			resources := v1.StaroidNamespaceResources{}
+			resources.Services.Items = append([]corev1.Service{}, ns.Services...)
+			writeJSON(w, resources)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "namespace not found")
+}
+
+func newShellService(namespace string) corev1.Service {
+	return corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "shell",
+			Namespace: namespace,
+			Labels: map[string]string{
+				constants.K8S_LABEL_KEY_RESOURCE_SYSTEM: constants.K8S_LABEL_VALUE_RESOURCE_SYSTEM_SHELL,
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "tunnel", Port: constants.TunnelServicePort},
+				{Name: "kubeproxy", Port: constants.KubeproxyPort},
+			},
+		},
+	}
+}
+
+func removeShellService(services []corev1.Service) []corev1.Service {
+	remaining := make([]corev1.Service, 0, len(services))
+	for _, service := range services {
+		if service.Labels[constants.K8S_LABEL_KEY_RESOURCE_SYSTEM] != constants.K8S_LABEL_VALUE_RESOURCE_SYSTEM_SHELL {
+			remaining = append(remaining, service)
+		}
+	}
+	return remaining
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
@@ -0,0 +1,132 @@
+package fake
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+
+	v1 "github.com/staroids/starctl/pkg/api/v1"
+	"github.com/staroids/starctl/pkg/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(t *testing.T) (*Server, v1.StaroidOrg, v1.StaroidCluster, *v1.V1) {
+	server := NewServer()
+	server.SetToken("test-token")
+	os.Setenv(constants.EnvStaroidApiServer, server.URL)
+	os.Setenv(constants.EnvStaroidAccessToken, server.Token())
+	t.Cleanup(func() {
+		server.Close()
+		os.Unsetenv(constants.EnvStaroidApiServer)
+		os.Unsetenv(constants.EnvStaroidAccessToken)
+	})
+
+	org := server.AddOrg("GITHUB", "staroids")
+	cluster := server.AddCluster(org, "default", "aws", "us-west2")
+	return server, org, cluster, &v1.V1{}
+}
+
+func TestOrgsAndClusters(t *testing.T) {
+	_, org, cluster, client := newTestServer(t)
+
+	orgs, err := client.Org().GetAll()
+	assert.Nil(t, err)
+	assert.Equal(t, []v1.StaroidOrg{org}, *orgs)
+
+	clusters, err := client.Cluster().WithOrg(org.Provider, org.Name).GetAll()
+	assert.Nil(t, err)
+	assert.Equal(t, []v1.StaroidCluster{cluster}, *clusters)
+
+	_, err = client.Cluster().WithOrg("GITHUB", "unknown").GetAll()
+	assert.True(t, v1.IsNotFound(err))
+
+	// not found in the list reports the list request
+	_, err = client.Cluster().WithOrg(org.Provider, org.Name).Get("unknown")
+	assert.EqualError(t, err, "404 Cluster unknown not found (GET /orgs/GITHUB/staroids/vc)")
+
+	_, err = client.Namespace().WithOrg(org.Provider, org.Name).WithClusterID(cluster.ID).Get("unknown")
+	assert.EqualError(t, err, fmt.Sprintf("404 Alias unknown not found (GET /orgs/GITHUB/staroids/vc/%d/instance)", cluster.ID))
+}
+
+func TestNamespaceLifecycle(t *testing.T) {
+	server, org, cluster, client := newTestServer(t)
+	builder := client.Namespace().WithOrg(org.Provider, org.Name).WithClusterID(cluster.ID)
+
+	commit, _ := v1.NewCommitFromCommitLocation("GITHUB/staroids/namespace:master")
+	ns, err := builder.WithCommit(commit).Create("dev")
+	assert.Nil(t, err)
+	assert.Equal(t, v1.NamespacePhaseScheduled, ns.Phase)
+	assert.Equal(t, "namespace", server.Namespace(ns.ID).Commit.Repo)
+
+	_, err = builder.Create("dev")
+	assert.True(t, v1.IsConflict(err))
+
+	for _, phase := range []v1.NamespacePhase{v1.NamespacePhaseStarting, v1.NamespacePhaseRunning, v1.NamespacePhaseRunning} {
+		ns, err = builder.GetById(ns.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, phase, ns.Phase)
+	}
+
+	// shell service
+	err = builder.ShellStartById(ns.ID)
+	assert.Nil(t, err)
+	shell, err := builder.WithName(ns.Namespace).GetShellService()
+	assert.Nil(t, err)
+	assert.NotNil(t, shell)
+
+	// pause and resume
+	ns, err = builder.StopById(ns.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, v1.NamespaceStatusPause, ns.Status)
+	ns, _ = builder.GetById(ns.ID)
+	assert.Equal(t, v1.NamespacePhasePaused, ns.Phase)
+	shell, _ = builder.GetShellService()
+	assert.Nil(t, shell)
+
+	_, err = builder.StartById(ns.ID)
+	assert.Nil(t, err)
+	ns, _ = builder.GetById(ns.ID)
+	assert.Equal(t, v1.NamespacePhaseStarting, ns.Phase)
+
+	// delete
+	_, err = builder.Delete("dev")
+	assert.Nil(t, err)
+	ns, _ = builder.GetById(ns.ID)
+	assert.Equal(t, v1.NamespacePhaseRemoved, ns.Phase)
+	_, err = builder.Get("dev")
+	assert.True(t, v1.IsNotFound(err))
+}
+
+func TestManualPhase(t *testing.T) {
+	server, org, cluster, client := newTestServer(t)
+	server.SetManualPhase(true)
+	added := server.AddNamespace(cluster, "dev", v1.NamespacePhaseScheduled)
+	builder := client.Namespace().WithOrg(org.Provider, org.Name).WithClusterID(cluster.ID)
+
+	ns, _ := builder.GetById(added.ID)
+	assert.Equal(t, v1.NamespacePhaseScheduled, ns.Phase)
+
+	server.Advance(added.ID)
+	ns, _ = builder.GetById(added.ID)
+	assert.Equal(t, v1.NamespacePhaseStarting, ns.Phase)
+
+	server.SetPhase(added.ID, v1.NamespacePhaseRunning)
+	ns, _ = builder.Get("dev")
+	assert.Equal(t, v1.NamespacePhaseRunning, ns.Phase)
+}
+
+func TestFaultInjection(t *testing.T) {
+	server, _, _, client := newTestServer(t)
+	server.InjectFault(Fault{Method: "GET", Path: "/orgs", Status: http.StatusInternalServerError, Times: 1})
+
+	_, err := client.Org().GetAll()
+	assert.Equal(t, "500 injected fault (GET /orgs/)", err.Error())
+
+	_, err = client.Org().GetAll()
+	assert.Nil(t, err)
+
+	os.Setenv(constants.EnvStaroidAccessToken, "wrong-token")
+	_, err = client.Org().GetAll()
+	assert.True(t, v1.IsUnauthorized(err))
+}
@@ -0,0 +1,91 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var fastPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  time.Millisecond,
+	MaxBackoff:  2 * time.Millisecond,
+}
+
+func newFlakyServer(failures int32, status int, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(calls, 1) <= failures {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(status)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestRetryIdempotentRequest(t *testing.T) {
+	var calls int32
+	server := newFlakyServer(2, http.StatusBadGateway, &calls)
+	defer server.Close()
+
+	client := &http.Client{Transport: NewRetryTransport(nil, fastPolicy)}
+	req, _ := http.NewRequest("PUT", server.URL, strings.NewReader("{}"))
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), calls)
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	var calls int32
+	server := newFlakyServer(10, http.StatusTooManyRequests, &calls)
+	defer server.Close()
+
+	client := &http.Client{Transport: NewRetryTransport(nil, fastPolicy)}
+	resp, err := client.Get(server.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, int32(3), calls)
+}
+
+func TestNoRetryForPost(t *testing.T) {
+	var calls int32
+	server := newFlakyServer(1, http.StatusServiceUnavailable, &calls)
+	defer server.Close()
+
+	client := &http.Client{Transport: NewRetryTransport(nil, fastPolicy)}
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader("{}"))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}
+	resp.Header.Set("Retry-After", "7")
+	delay, ok := parseRetryAfter(resp)
+	assert.True(t, ok)
+	assert.Equal(t, 7*time.Second, delay)
+
+	resp.Header.Set("Retry-After", "3600")
+	delay, _ = parseRetryAfter(resp)
+	assert.Equal(t, maxRetryAfter, delay)
+
+	resp.StatusCode = http.StatusBadGateway
+	_, ok = parseRetryAfter(resp)
+	assert.False(t, ok)
+}
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempt := 1; attempt < 8; attempt++ {
+		d := policy.Backoff(attempt)
+		assert.True(t, d >= 50*time.Millisecond)
+		assert.True(t, d <= time.Second)
+	}
+}
@@ -0,0 +1,173 @@
+package transport
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/staroids/starctl/pkg/constants"
+)
+
+// maxTraceBodySize limits how much of a body is printed
+const maxTraceBodySize = 16 * 1024
+
+// Trace levels
+const (
+	// TraceRequest logs method, url, status and latency
+	TraceRequest = 1
+	// TraceHeaders additionally logs request and response headers
+	TraceHeaders = 2
+	// TraceBody additionally logs request and response body
+	TraceBody = 3
+)
+
+// TraceTransport logs requests and responses. Authorization header is always redacted.
+type TraceTransport struct {
+	Next http.RoundTripper
+	// Out is where logs are written. os.Stderr when nil
+	Out io.Writer
+	// Level is one of TraceRequest, TraceHeaders, TraceBody. 0 logs nothing but curl command
+	Level int
+	// Curl prints curl command equivalent to each request
+	Curl bool
+}
+
+func NewTraceTransport(next http.RoundTripper, out io.Writer, level int, curl bool) *TraceTransport {
+	return &TraceTransport{
+		Next:  next,
+		Out:   out,
+		Level: level,
+		Curl:  curl,
+	}
+}
+
+func (t *TraceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	out := t.Out
+	if out == nil {
+		out = os.Stderr
+	}
+
+	var reqBody []byte
+	if req.Body != nil && req.GetBody != nil && (t.Curl || t.Level >= TraceBody) {
+		if body, err := req.GetBody(); err == nil {
+			reqBody, _ = ioutil.ReadAll(body)
+			body.Close()
+		}
+	}
+
+	if t.Curl {
+		fmt.Fprintf(out, "%s\n", CurlCommand(req, reqBody))
+	}
+	if t.Level >= TraceRequest {
+		fmt.Fprintf(out, "> %s %s\n", req.Method, req.URL)
+	}
+	if t.Level >= TraceHeaders {
+		writeHeaders(out, "> ", req.Header)
+	}
+	if t.Level >= TraceBody && len(reqBody) > 0 {
+		writeBody(out, "> ", reqBody)
+	}
+
+	start := time.Now()
+	resp, err := next.RoundTrip(req)
+	latency := time.Since(start).Round(time.Millisecond)
+
+	if err != nil {
+		if t.Level >= TraceRequest {
+			fmt.Fprintf(out, "< %s %s error: %v (%v)\n", req.Method, req.URL, err, latency)
+		}
+		return resp, err
+	}
+
+	if t.Level >= TraceRequest {
+		fmt.Fprintf(out, "< %s %s %s (%v)\n", req.Method, req.URL, resp.Status, latency)
+	}
+	if t.Level >= TraceHeaders {
+		writeHeaders(out, "< ", resp.Header)
+	}
+	if t.Level >= TraceBody && resp.Body != nil {
+		respBody, readErr := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+		if readErr != nil {
+			return nil, readErr
+		}
+		writeBody(out, "< ", respBody)
+	}
+	return resp, nil
+}
+
+// RedactHeader returns header value safe to print
+func RedactHeader(name string, value string) string {
+	if !strings.EqualFold(name, "Authorization") {
+		return value
+	}
+	if i := strings.IndexByte(value, ' '); i > 0 {
+		return value[:i] + " <redacted>"
+	}
+	return "<redacted>"
+}
+
+// CurlCommand returns curl command equivalent to req.
+// Access token is referenced by environment variable instead of printing it.
+func CurlCommand(req *http.Request, body []byte) string {
+	args := []string{"curl", "-X", req.Method}
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range req.Header[name] {
+			if strings.EqualFold(name, "Authorization") {
+				scheme := strings.SplitN(value, " ", 2)[0]
+				args = append(args, "-H", fmt.Sprintf("\"%s: %s ${%s}\"", name, scheme, constants.EnvStaroidAccessToken))
+				continue
+			}
+			args = append(args, "-H", shellQuote(fmt.Sprintf("%s: %s", name, value)))
+		}
+	}
+	if len(body) > 0 {
+		args = append(args, "-d", shellQuote(string(body)))
+	}
+	args = append(args, shellQuote(req.URL.String()))
+	return strings.Join(args, " ")
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+func writeHeaders(out io.Writer, prefix string, header http.Header) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range header[name] {
+			fmt.Fprintf(out, "%s%s: %s\n", prefix, name, RedactHeader(name, value))
+		}
+	}
+}
+
+func writeBody(out io.Writer, prefix string, body []byte) {
+	truncated := ""
+	if len(body) > maxTraceBodySize {
+		body = body[:maxTraceBodySize]
+		truncated = fmt.Sprintf("\n%s... (truncated)", prefix)
+	}
+	text := strings.TrimRight(string(body), "\n")
+	fmt.Fprintf(out, "%s%s%s\n", prefix, strings.Replace(text, "\n", "\n"+prefix, -1), truncated)
+}
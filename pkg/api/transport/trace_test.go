@@ -0,0 +1,45 @@
+package transport
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceRedactsToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"staroids"}]`)
+	}))
+	defer server.Close()
+
+	out := &bytes.Buffer{}
+	client := &http.Client{Transport: NewTraceTransport(nil, out, TraceBody, true)}
+	req, _ := http.NewRequest("PUT", server.URL+"/orgs/", strings.NewReader(`{"a":"it's"}`))
+	req.Header.Set("Authorization", "token secret-token")
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+
+	// body is still readable by the caller
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, `[{"name":"staroids"}]`, string(body))
+
+	log := out.String()
+	assert.NotContains(t, log, "secret-token")
+	assert.Contains(t, log, "> Authorization: token <redacted>")
+	assert.Contains(t, log, fmt.Sprintf("> PUT %s/orgs/", server.URL))
+	assert.Contains(t, log, fmt.Sprintf("< PUT %s/orgs/ 200 OK", server.URL))
+	assert.Contains(t, log, `< [{"name":"staroids"}]`)
+	assert.Contains(t, log, fmt.Sprintf(`curl -X PUT -H "Authorization: token ${STAROID_ACCESS_TOKEN}" -d '{"a":"it'\''s"}' '%s/orgs/'`, server.URL))
+}
+
+func TestRedactHeader(t *testing.T) {
+	assert.Equal(t, "token <redacted>", RedactHeader("authorization", "token abc"))
+	assert.Equal(t, "<redacted>", RedactHeader("Authorization", "abc"))
+	assert.Equal(t, "application/json", RedactHeader("Content-Type", "application/json"))
+}
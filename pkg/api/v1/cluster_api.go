@@ -1,14 +1,36 @@
 package v1
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"time"
+
+	"github.com/staroids/starctl/pkg/constants"
 )
 
+type ClusterCreateRequestMessage struct {
+	Name string     `json:"name"`
+	Ske  StaroidSke `json:"ske"`
+}
+
 type ClusterRequestBuilder struct {
 	v1       *V1
+	ctx      context.Context
 	Provider string
 	Org      string
+	// PollInterval is interval of polling in WaitForDeletion(). 0 uses StatusPollingIntervalSec
+	PollInterval time.Duration
+}
+
+// WithContext sets the context used by requests made from this builder.
+func (b *ClusterRequestBuilder) WithContext(ctx context.Context) *ClusterRequestBuilder {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	b.ctx = ctx
+	return b
 }
 
 func (b *ClusterRequestBuilder) WithOrg(provider string, org string) *ClusterRequestBuilder {
@@ -17,13 +39,18 @@ func (b *ClusterRequestBuilder) WithOrg(provider string, org string) *ClusterReq
 	return b
 }
 
+func (b *ClusterRequestBuilder) WithPollInterval(pollInterval time.Duration) *ClusterRequestBuilder {
+	b.PollInterval = pollInterval
+	return b
+}
+
 func (b *ClusterRequestBuilder) GetAll() (*[]StaroidCluster, error) {
 	if b.Provider == "" || b.Org == "" {
 		return nil, fmt.Errorf("Org information is not set. call withOrg()")
 	}
 
 	client := b.v1.HttpClient()
-	req, err := b.v1.NewGetRequest(fmt.Sprintf("/orgs/%s/%s/vc", b.Provider, b.Org))
+	req, err := b.v1.NewRequestWithContext(b.ctx, "GET", fmt.Sprintf("/orgs/%s/%s/vc", b.Provider, b.Org), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -33,6 +60,11 @@ func (b *ClusterRequestBuilder) GetAll() (*[]StaroidCluster, error) {
 	}
 	defer resp.Body.Close()
 
+	err = GetApiErrorFromResponse(resp, map[int]string{})
+	if err != nil {
+		return nil, err
+	}
+
 	// parse json response
 	clusters := make([]StaroidCluster, 0)
 	decoder := json.NewDecoder(resp.Body)
@@ -43,3 +75,153 @@ func (b *ClusterRequestBuilder) GetAll() (*[]StaroidCluster, error) {
 
 	return &clusters, nil
 }
+
+// Create creates a cluster on the ske
+func (b *ClusterRequestBuilder) Create(name string, ske StaroidSke) (*StaroidCluster, error) {
+	if b.Provider == "" || b.Org == "" {
+		return nil, fmt.Errorf("Org information is not set. call withOrg()")
+	}
+
+	client := b.v1.HttpClient()
+
+	requestBody := ClusterCreateRequestMessage{
+		Name: name,
+		Ske:  ske,
+	}
+	jsonValue, _ := json.Marshal(&requestBody)
+	jsonData := bytes.NewBuffer(jsonValue)
+
+	req, err := b.v1.NewRequestWithContext(b.ctx, "POST", fmt.Sprintf("/orgs/%s/%s/vc", b.Provider, b.Org), jsonData)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	err = GetApiErrorFromResponse(resp, map[int]string{
+		409: "Already exists",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// parse json response
+	cluster := StaroidCluster{}
+	decoder := json.NewDecoder(resp.Body)
+	err = decoder.Decode(&cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cluster, nil
+}
+
+// Get finds cluster by name
+func (b *ClusterRequestBuilder) Get(name string) (*StaroidCluster, error) {
+	clusters, err := b.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for i := range *clusters {
+		if (*clusters)[i].Name == name {
+			return &(*clusters)[i], nil
+		}
+	}
+
+	return nil, b.v1.notFoundError("GET", fmt.Sprintf("/orgs/%s/%s/vc", b.Provider, b.Org), fmt.Sprintf("Cluster %s not found", name))
+}
+
+func (b *ClusterRequestBuilder) GetById(clusterID int64) (*StaroidCluster, error) {
+	if b.Provider == "" || b.Org == "" {
+		return nil, fmt.Errorf("Org information is not set. call withOrg()")
+	}
+
+	client := b.v1.HttpClient()
+	req, err := b.v1.NewRequestWithContext(b.ctx, "GET", fmt.Sprintf("/orgs/%s/%s/vc/%d", b.Provider, b.Org, clusterID), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	err = GetApiErrorFromResponse(resp, map[int]string{})
+	if err != nil {
+		return nil, err
+	}
+
+	// parse json response
+	cluster := StaroidCluster{}
+	decoder := json.NewDecoder(resp.Body)
+	err = decoder.Decode(&cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cluster, nil
+}
+
+// Delete deletes cluster by name
+func (b *ClusterRequestBuilder) Delete(name string) error {
+	cluster, err := b.Get(name)
+	if err != nil {
+		return err
+	}
+	return b.DeleteById(cluster.ID)
+}
+
+// DeleteById deletes the cluster. The server refuses to delete a cluster having namespaces
+func (b *ClusterRequestBuilder) DeleteById(clusterID int64) error {
+	if b.Provider == "" || b.Org == "" {
+		return fmt.Errorf("Org information is not set. call withOrg()")
+	}
+
+	client := b.v1.HttpClient()
+	req, err := b.v1.NewRequestWithContext(b.ctx, "DELETE", fmt.Sprintf("/orgs/%s/%s/vc/%d", b.Provider, b.Org, clusterID), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return GetApiErrorFromResponse(resp, map[int]string{
+		409: "Cluster has namespaces",
+	})
+}
+
+// WaitForDeletion polls the cluster every PollInterval until it is not found, or ctx is done
+func (b *ClusterRequestBuilder) WaitForDeletion(ctx context.Context, clusterID int64) error {
+	pollInterval := b.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = constants.StatusPollingIntervalSec * time.Second
+	}
+	builder := *b
+	builder.WithContext(ctx)
+
+	for {
+		_, err := builder.GetById(clusterID)
+		if IsNotFound(err) {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-time.After(pollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
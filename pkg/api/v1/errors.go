@@ -0,0 +1,134 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBodySize limits how much of an error response body is read
+const maxErrorBodySize = 64 * 1024
+
+// StaroidAPIError is returned when the api server responds with a non 2xx status
+type StaroidAPIError struct {
+	StatusCode int
+	Message    string
+	Method     string
+	Path       string
+}
+
+func (e *StaroidAPIError) Error() string {
+	msg := fmt.Sprintf("%d", e.StatusCode)
+	if e.Message != "" {
+		msg = fmt.Sprintf("%s %s", msg, e.Message)
+	}
+	if e.Method != "" || e.Path != "" {
+		msg = fmt.Sprintf("%s (%s %s)", msg, e.Method, e.Path)
+	}
+	return msg
+}
+
+// IsNotFound returns true if err is a StaroidAPIError with status 404
+func IsNotFound(err error) bool {
+	return hasStatusCode(err, http.StatusNotFound)
+}
+
+// IsConflict returns true if err is a StaroidAPIError with status 409
+func IsConflict(err error) bool {
+	return hasStatusCode(err, http.StatusConflict)
+}
+
+// IsUnauthorized returns true if err is a StaroidAPIError with status 401
+func IsUnauthorized(err error) bool {
+	return hasStatusCode(err, http.StatusUnauthorized)
+}
+
+// IsForbidden returns true if err is a StaroidAPIError with status 403
+func IsForbidden(err error) bool {
+	return hasStatusCode(err, http.StatusForbidden)
+}
+
+func hasStatusCode(err error, statusCode int) bool {
+	var apiErr *StaroidAPIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == statusCode
+	}
+	return false
+}
+
+func GetApiErrorFromResponse(resp *http.Response, customErrorMessage map[int]string) error {
+	message := map[int]string{
+		401: "Not authorized",
+		403: "Forbidden",
+		404: "Not found",
+	}
+
+	for k, v := range customErrorMessage {
+		message[k] = v
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	apiErr := &StaroidAPIError{
+		StatusCode: resp.StatusCode,
+		Message:    message[resp.StatusCode],
+	}
+	if resp.Request != nil {
+		apiErr.Method = resp.Request.Method
+		apiErr.Path = resp.Request.URL.Path
+	}
+
+	if serverMessage := readErrorMessage(resp.Body); serverMessage != "" {
+		if apiErr.Message == "" {
+			apiErr.Message = serverMessage
+		} else {
+			apiErr.Message = fmt.Sprintf("%s: %s", apiErr.Message, serverMessage)
+		}
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
+
+// readErrorMessage extracts a human readable message from an error response body.
+// json bodies with 'message' or 'error' field and short plain text bodies are supported.
+func readErrorMessage(body io.Reader) string {
+	if body == nil {
+		return ""
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(body, maxErrorBodySize))
+	if err != nil {
+		return ""
+	}
+	text := strings.TrimSpace(string(data))
+	if text == "" {
+		return ""
+	}
+
+	parsed := struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}{}
+	if json.Unmarshal(data, &parsed) == nil {
+		if parsed.Message != "" {
+			return parsed.Message
+		}
+		return parsed.Error
+	}
+
+	// ignore html error pages
+	if strings.HasPrefix(text, "<") {
+		return ""
+	}
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	return text
+}
@@ -0,0 +1,71 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/staroids/starctl/pkg/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+type apiErrorTestStruct struct {
+	status  int
+	body    string
+	message string
+}
+
+var apiErrorTestData = []apiErrorTestStruct{
+	{404, "", "Not found"},
+	{404, `{"message": "no such org"}`, "Not found: no such org"},
+	{409, `{"error": "instance exists"}`, "Already exists: instance exists"},
+	{500, "internal failure\nstack trace", "internal failure"},
+	{402, "", "Payment Required"},
+	{502, "<html><body>Bad gateway</body></html>", "Bad Gateway"},
+}
+
+func TestGetApiErrorFromResponse(t *testing.T) {
+	for _, testData := range apiErrorTestData {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(testData.status)
+			fmt.Fprint(w, testData.body)
+		}))
+
+		resp, err := http.Post(server.URL+"/orgs/GITHUB/staroids/vc/1/instance", "application/json", nil)
+		assert.Nil(t, err)
+
+		err = GetApiErrorFromResponse(resp, map[int]string{409: "Already exists"})
+		resp.Body.Close()
+		server.Close()
+
+		apiErr, ok := err.(*StaroidAPIError)
+		assert.True(t, ok)
+		assert.Equal(t, testData.status, apiErr.StatusCode)
+		assert.Equal(t, testData.message, apiErr.Message)
+		assert.Equal(t, "POST", apiErr.Method)
+		assert.Equal(t, "/orgs/GITHUB/staroids/vc/1/instance", apiErr.Path)
+	}
+}
+
+func TestGetAllReturnsApiError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	os.Setenv(constants.EnvStaroidApiServer, server.URL)
+	defer os.Unsetenv(constants.EnvStaroidApiServer)
+
+	v := &V1{}
+	_, err := v.Org().GetAll()
+	assert.True(t, IsUnauthorized(err))
+
+	_, err = v.Cluster().WithOrg("GITHUB", "staroids").GetAll()
+	assert.True(t, IsUnauthorized(err))
+	assert.False(t, IsNotFound(err))
+
+	_, err = v.Namespace().WithOrg("GITHUB", "staroids").WithClusterID(1).Get("dev")
+	assert.True(t, IsUnauthorized(err))
+}
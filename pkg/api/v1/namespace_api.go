@@ -2,9 +2,10 @@ package v1
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"os"
+	"time"
 
 	"github.com/staroids/starctl/pkg/constants"
 	corev1 "k8s.io/api/core/v1"
@@ -17,12 +18,25 @@ type NamespaceStartRequestMessage struct {
 
 type NamespaceRequestBuilder struct {
 	v1          *V1
+	ctx         context.Context
 	Provider    string
 	Org         string
 	ClusterID   int64
 	NamespaceID int64
 	Name        string // kubernetes namespace
 	Commit      *Commit
+
+	// PollInterval is interval of status polling in Watch() and WaitFor()
+	PollInterval time.Duration
+}
+
+// WithContext sets the context used by requests made from this builder.
+func (b *NamespaceRequestBuilder) WithContext(ctx context.Context) *NamespaceRequestBuilder {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	b.ctx = ctx
+	return b
 }
 
 func (b *NamespaceRequestBuilder) WithOrg(provider string, org string) *NamespaceRequestBuilder {
@@ -46,6 +60,11 @@ func (b *NamespaceRequestBuilder) WithName(name string) *NamespaceRequestBuilder
 	return b
 }
 
+func (b *NamespaceRequestBuilder) WithPollInterval(pollInterval time.Duration) *NamespaceRequestBuilder {
+	b.PollInterval = pollInterval
+	return b
+}
+
 func (b *NamespaceRequestBuilder) WithCommit(commit *Commit) *NamespaceRequestBuilder {
 	b.Commit = commit
 	return b
@@ -73,7 +92,9 @@ func (b *NamespaceRequestBuilder) Create(alias string) (*StaroidNamespace, error
 	jsonValue, _ := json.Marshal(&requestBody)
 	jsonData := bytes.NewBuffer(jsonValue)
 
-	req, err := b.v1.NewPostRequest(
+	req, err := b.v1.NewRequestWithContext(
+		b.ctx,
+		"POST",
 		fmt.Sprintf("/orgs/%s/%s/vc/%d/instance", b.Provider, b.Org, b.ClusterID),
 		jsonData,
 	)
@@ -114,7 +135,7 @@ func (b *NamespaceRequestBuilder) GetAll() (*[]StaroidNamespace, error) {
 	}
 
 	client := b.v1.HttpClient()
-	req, err := b.v1.NewGetRequest(fmt.Sprintf("/orgs/%s/%s/vc/%d/instance", b.Provider, b.Org, b.ClusterID))
+	req, err := b.v1.NewRequestWithContext(b.ctx, "GET", fmt.Sprintf("/orgs/%s/%s/vc/%d/instance", b.Provider, b.Org, b.ClusterID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -124,6 +145,11 @@ func (b *NamespaceRequestBuilder) GetAll() (*[]StaroidNamespace, error) {
 	}
 	defer resp.Body.Close()
 
+	err = GetApiErrorFromResponse(resp, map[int]string{})
+	if err != nil {
+		return nil, err
+	}
+
 	// parse json response
 	namespaces := make([]StaroidNamespace, 0)
 	decoder := json.NewDecoder(resp.Body)
@@ -161,7 +187,7 @@ func (b *NamespaceRequestBuilder) GetAllResources() (*StaroidNamespaceResources,
 	}
 
 	client := b.v1.HttpClient()
-	req, err := b.v1.NewGetRequest(fmt.Sprintf("/namespace/%s", b.Name))
+	req, err := b.v1.NewRequestWithContext(b.ctx, "GET", fmt.Sprintf("/namespace/%s", b.Name), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -171,6 +197,11 @@ func (b *NamespaceRequestBuilder) GetAllResources() (*StaroidNamespaceResources,
 	}
 	defer resp.Body.Close()
 
+	err = GetApiErrorFromResponse(resp, map[int]string{})
+	if err != nil {
+		return nil, err
+	}
+
 	// parse json response
 	resources := StaroidNamespaceResources{}
 	decoder := json.NewDecoder(resp.Body)
@@ -214,7 +245,7 @@ func (b *NamespaceRequestBuilder) Stop(alias string) (*StaroidNamespace, error)
 		return nil, err
 	}
 
-	return b.StartById(ns.ID)
+	return b.StopById(ns.ID)
 }
 
 func (b *NamespaceRequestBuilder) StopById(namespaceID int64) (*StaroidNamespace, error) {
@@ -238,7 +269,7 @@ func (b *NamespaceRequestBuilder) namespaceOP(namespaceID int64, method string,
 	}
 
 	path := fmt.Sprintf("/orgs/%s/%s/vc/%d/instance/%d%s", b.Provider, b.Org, b.ClusterID, namespaceID, opPath)
-	req, err := b.v1.NewRequest(method, path, nil)
+	req, err := b.v1.NewRequestWithContext(b.ctx, method, path, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -276,8 +307,7 @@ func (b *NamespaceRequestBuilder) Get(alias string) (*StaroidNamespace, error) {
 	// find namespace by alias
 	namespaces, err := b.GetAll()
 	if err != nil {
-		fmt.Printf("%v\n", err)
-		os.Exit(1)
+		return nil, err
 	}
 	var found *StaroidNamespace = nil
 	for _, ns := range *namespaces {
@@ -288,7 +318,7 @@ func (b *NamespaceRequestBuilder) Get(alias string) (*StaroidNamespace, error) {
 	}
 
 	if found == nil {
-		return nil, fmt.Errorf("Alias %s not found", alias)
+		return nil, b.v1.notFoundError("GET", fmt.Sprintf("/orgs/%s/%s/vc/%d/instance", b.Provider, b.Org, b.ClusterID), fmt.Sprintf("Alias %s not found", alias))
 	}
 
 	return found, nil
@@ -306,7 +336,7 @@ func (b *NamespaceRequestBuilder) ShellStartById(namespaceID int64) error {
 	client := b.v1.HttpClient()
 
 	path := fmt.Sprintf("/orgs/%s/%s/vc/%d/instance/%d/shell", b.Provider, b.Org, b.ClusterID, namespaceID)
-	req, err := b.v1.NewPostRequest(path, nil)
+	req, err := b.v1.NewRequestWithContext(b.ctx, "POST", path, nil)
 	if err != nil {
 		return err
 	}
@@ -335,7 +365,7 @@ func (b *NamespaceRequestBuilder) ShellStopById(namespaceID int64) error {
 	client := b.v1.HttpClient()
 
 	path := fmt.Sprintf("/orgs/%s/%s/vc/%d/instance/%d/shell", b.Provider, b.Org, b.ClusterID, namespaceID)
-	req, err := b.v1.NewDeleteRequest(path)
+	req, err := b.v1.NewRequestWithContext(b.ctx, "DELETE", path, nil)
 	if err != nil {
 		return err
 	}
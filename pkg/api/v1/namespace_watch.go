@@ -0,0 +1,208 @@
+package v1
+
+import (
+	"context"
+	"time"
+
+	"github.com/staroids/starctl/pkg/constants"
+)
+
+// NamespaceEvent is sent by Watch() when phase or status of a namespace changes
+type NamespaceEvent struct {
+	Time      time.Time
+	Namespace *StaroidNamespace
+	// Err is set when polling failed. It is the last event before the channel is closed
+	Err error
+}
+
+// NamespacePredicate tests namespace state in WaitFor()
+type NamespacePredicate func(ns *StaroidNamespace) bool
+
+// PhaseIn returns predicate that is true when namespace is in one of phases
+func PhaseIn(phases ...NamespacePhase) NamespacePredicate {
+	return func(ns *StaroidNamespace) bool {
+		for _, phase := range phases {
+			if ns.Phase == phase {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// PhaseNotIn returns predicate that is true when namespace is in none of phases
+func PhaseNotIn(phases ...NamespacePhase) NamespacePredicate {
+	in := PhaseIn(phases...)
+	return func(ns *StaroidNamespace) bool {
+		return !in(ns)
+	}
+}
+
+func (b *NamespaceRequestBuilder) pollInterval() time.Duration {
+	if b.PollInterval > 0 {
+		return b.PollInterval
+	}
+	return constants.StatusPollingIntervalSec * time.Second
+}
+
+// Watch polls the namespace every PollInterval and sends an event whenever its phase or status changes.
+// The first event reports the current state. The channel is closed when ctx is done or polling fails.
+func (b *NamespaceRequestBuilder) Watch(ctx context.Context, namespaceID int64) <-chan NamespaceEvent {
+	events := make(chan NamespaceEvent)
+	builder := *b
+	builder.WithContext(ctx)
+
+	go func() {
+		defer close(events)
+
+		var last *StaroidNamespace
+		for {
+			ns, err := builder.GetById(namespaceID)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				select {
+				case events <- NamespaceEvent{Time: time.Now(), Err: err}:
+				case <-ctx.Done():
+				}
+				return
+			}
+
+			if last == nil || last.Phase != ns.Phase || last.Status != ns.Status {
+				select {
+				case events <- NamespaceEvent{Time: time.Now(), Namespace: ns}:
+				case <-ctx.Done():
+					return
+				}
+			}
+			last = ns
+
+			timer := time.NewTimer(builder.pollInterval())
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+	return events
+}
+
+// WaitFor blocks until predicate is true for the namespace.
+// When ctx is done or polling fails, the last known state is returned with the error.
+func (b *NamespaceRequestBuilder) WaitFor(ctx context.Context, namespaceID int64, predicate NamespacePredicate) (*StaroidNamespace, error) {
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var last *StaroidNamespace
+	for event := range b.Watch(watchCtx, namespaceID) {
+		if event.Err != nil {
+			return last, event.Err
+		}
+		last = event.Namespace
+		if predicate(last) {
+			return last, nil
+		}
+	}
+	return last, ctx.Err()
+}
+
+// NamespaceListEvent is sent by WatchAll() when phase or status of any watched namespace changes
+type NamespaceListEvent struct {
+	Time time.Time
+	// Changed are the namespaces changed since the previous event, in list order.
+	// A namespace disappeared from the list is reported in REMOVED phase
+	Changed []StaroidNamespace
+	// Namespaces are current states of all watched namespaces, in list order. Removed namespaces are not included
+	Namespaces []StaroidNamespace
+	// Err is set when polling failed. It is the last event before the channel is closed
+	Err error
+}
+
+// WatchAll polls namespaces of the cluster every PollInterval with a single GetAll() request,
+// and sends an event whenever phase or status of any watched namespace changes.
+// Only namespaces having one of aliases are watched. All namespaces are watched when aliases is empty.
+// The first event reports the current states. The channel is closed when ctx is done or polling fails.
+func (b *NamespaceRequestBuilder) WatchAll(ctx context.Context, aliases ...string) <-chan NamespaceListEvent {
+	events := make(chan NamespaceListEvent)
+	builder := *b
+	builder.WithContext(ctx)
+
+	watched := func(ns *StaroidNamespace) bool {
+		if len(aliases) == 0 {
+			return true
+		}
+		for _, alias := range aliases {
+			if ns.Alias == alias {
+				return true
+			}
+		}
+		return false
+	}
+
+	go func() {
+		defer close(events)
+
+		var last []StaroidNamespace
+		for {
+			namespaces, err := builder.GetAll()
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				select {
+				case events <- NamespaceListEvent{Time: time.Now(), Err: err}:
+				case <-ctx.Done():
+				}
+				return
+			}
+
+			lastByID := make(map[int64]*StaroidNamespace)
+			for i := range last {
+				lastByID[last[i].ID] = &last[i]
+			}
+
+			current := make([]StaroidNamespace, 0)
+			changed := make([]StaroidNamespace, 0)
+			for _, ns := range *namespaces {
+				if !watched(&ns) || ns.Phase == NamespacePhaseRemoved {
+					continue
+				}
+				current = append(current, ns)
+				prev, ok := lastByID[ns.ID]
+				if !ok || prev.Phase != ns.Phase || prev.Status != ns.Status {
+					changed = append(changed, ns)
+				}
+				delete(lastByID, ns.ID)
+			}
+			for _, prev := range last {
+				if _, ok := lastByID[prev.ID]; ok {
+					removed := prev
+					removed.Phase = NamespacePhaseRemoved
+					removed.Status = NamespaceStatusInactive
+					changed = append(changed, removed)
+				}
+			}
+
+			if last == nil || len(changed) > 0 {
+				select {
+				case events <- NamespaceListEvent{Time: time.Now(), Changed: changed, Namespaces: current}:
+				case <-ctx.Done():
+					return
+				}
+			}
+			last = current
+
+			timer := time.NewTimer(builder.pollInterval())
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+	return events
+}
@@ -0,0 +1,113 @@
+package v1_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/staroids/starctl/pkg/api/fake"
+	v1 "github.com/staroids/starctl/pkg/api/v1"
+	"github.com/staroids/starctl/pkg/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func newWatchTestBuilder(t *testing.T) (*fake.Server, v1.StaroidCluster, *v1.NamespaceRequestBuilder) {
+	server := fake.NewServer()
+	os.Setenv(constants.EnvStaroidApiServer, server.URL)
+	t.Cleanup(func() {
+		server.Close()
+		os.Unsetenv(constants.EnvStaroidApiServer)
+	})
+
+	org := server.AddOrg("GITHUB", "staroids")
+	cluster := server.AddCluster(org, "default", "aws", "us-west2")
+	builder := (&v1.V1{}).Namespace().
+		WithOrg(org.Provider, org.Name).
+		WithClusterID(cluster.ID).
+		WithPollInterval(time.Millisecond)
+	return server, cluster, builder
+}
+
+func TestWatch(t *testing.T) {
+	server, cluster, builder := newWatchTestBuilder(t)
+	ns := server.AddNamespace(cluster, "dev", v1.NamespacePhaseScheduled)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	phases := make([]v1.NamespacePhase, 0)
+	for event := range builder.Watch(ctx, ns.ID) {
+		assert.Nil(t, event.Err)
+		phases = append(phases, event.Namespace.Phase)
+		if event.Namespace.Phase == v1.NamespacePhaseRunning {
+			cancel()
+		}
+	}
+	assert.Equal(t, []v1.NamespacePhase{v1.NamespacePhaseStarting, v1.NamespacePhaseRunning}, phases)
+}
+
+func TestWaitFor(t *testing.T) {
+	server, cluster, builder := newWatchTestBuilder(t)
+	ns := server.AddNamespace(cluster, "dev", v1.NamespacePhaseRunning)
+
+	_, err := builder.StopById(ns.ID)
+	assert.Nil(t, err)
+	paused, err := builder.WaitFor(context.Background(), ns.ID, v1.PhaseIn(v1.NamespacePhasePaused))
+	assert.Nil(t, err)
+	assert.Equal(t, v1.NamespacePhasePaused, paused.Phase)
+
+	// never reaches RUNNING without resume
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	last, err := builder.WaitFor(ctx, ns.ID, v1.PhaseIn(v1.NamespacePhaseRunning))
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, v1.NamespacePhasePaused, last.Phase)
+}
+
+func TestWaitForApiError(t *testing.T) {
+	server, cluster, builder := newWatchTestBuilder(t)
+	ns := server.AddNamespace(cluster, "dev", v1.NamespacePhaseScheduled)
+	server.InjectFault(fake.Fault{Method: "GET", Status: 404})
+
+	_, err := builder.WaitFor(context.Background(), ns.ID, v1.PhaseIn(v1.NamespacePhaseRunning))
+	assert.True(t, v1.IsNotFound(err))
+}
+
+func TestWatchAll(t *testing.T) {
+	server, cluster, builder := newWatchTestBuilder(t)
+	server.SetManualPhase(true)
+	dev := server.AddNamespace(cluster, "dev", v1.NamespacePhaseScheduled)
+	server.AddNamespace(cluster, "other", v1.NamespacePhaseRunning)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := builder.WatchAll(ctx, "dev")
+
+	// first event reports current state of the watched namespace only
+	event := <-events
+	assert.Nil(t, event.Err)
+	assert.Equal(t, 1, len(event.Changed))
+	assert.Equal(t, "dev", event.Changed[0].Alias)
+	assert.Equal(t, event.Changed, event.Namespaces)
+
+	server.SetPhase(dev.ID, v1.NamespacePhaseRunning)
+	event = <-events
+	assert.Equal(t, v1.NamespacePhaseRunning, event.Changed[0].Phase)
+
+	// disappearing from the list is reported as removed
+	server.SetPhase(dev.ID, v1.NamespacePhaseRemoved)
+	event = <-events
+	assert.Equal(t, v1.NamespacePhaseRemoved, event.Changed[0].Phase)
+	assert.Equal(t, 0, len(event.Namespaces))
+}
+
+func TestWatchAllApiError(t *testing.T) {
+	server, _, builder := newWatchTestBuilder(t)
+	server.InjectFault(fake.Fault{Method: "GET", Status: 500})
+
+	events := builder.WatchAll(context.Background())
+	event := <-events
+	assert.NotNil(t, event.Err)
+	_, open := <-events
+	assert.False(t, open)
+}
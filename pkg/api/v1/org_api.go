@@ -1,14 +1,27 @@
 package v1
 
-import "encoding/json"
+import (
+	"context"
+	"encoding/json"
+)
 
 type OrgRequestBuilder struct {
-	v1 *V1
+	v1  *V1
+	ctx context.Context
+}
+
+// WithContext sets the context used by requests made from this builder.
+func (b *OrgRequestBuilder) WithContext(ctx context.Context) *OrgRequestBuilder {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	b.ctx = ctx
+	return b
 }
 
 func (b *OrgRequestBuilder) GetAll() (*[]StaroidOrg, error) {
 	client := b.v1.HttpClient()
-	req, err := b.v1.NewGetRequest("/orgs/")
+	req, err := b.v1.NewRequestWithContext(b.ctx, "GET", "/orgs/", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -18,6 +31,11 @@ func (b *OrgRequestBuilder) GetAll() (*[]StaroidOrg, error) {
 	}
 	defer resp.Body.Close()
 
+	err = GetApiErrorFromResponse(resp, map[int]string{})
+	if err != nil {
+		return nil, err
+	}
+
 	// parse json response
 	orgs := make([]StaroidOrg, 0)
 	decoder := json.NewDecoder(resp.Body)
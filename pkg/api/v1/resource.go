@@ -11,6 +11,14 @@ type StaroidSke struct {
 	ID     string `json:"name"`
 	Cloud  string `json:"cloud"`
 	Region string `json:"region"`
+	// Available is false when clusters can not be created on the SKE. nil when the server does not tell
+	Available *bool `json:"available,omitempty"`
+}
+
+// IsAvailable returns true when clusters can be created on the SKE.
+// An SKE without availability is assumed available, and the server decides on cluster creation
+func (s *StaroidSke) IsAvailable() bool {
+	return s.Available == nil || *s.Available
 }
 
 type StaroidCluster struct {
@@ -21,15 +29,56 @@ type StaroidCluster struct {
 	Type  string     `json:"type"`
 }
 
+// NamespacePhase is lifecycle phase of a namespace
+type NamespacePhase string
+
+const (
+	NamespacePhaseScheduled NamespacePhase = "SCHEDULED"
+	NamespacePhaseStarting  NamespacePhase = "STARTING"
+	NamespacePhaseRunning   NamespacePhase = "RUNNING"
+	NamespacePhasePaused    NamespacePhase = "PAUSED"
+	NamespacePhaseRemoved   NamespacePhase = "REMOVED"
+)
+
+// NamespacePhases are all phases in lifecycle order
+var NamespacePhases = []NamespacePhase{
+	NamespacePhaseScheduled,
+	NamespacePhaseStarting,
+	NamespacePhaseRunning,
+	NamespacePhasePaused,
+	NamespacePhaseRemoved,
+}
+
+// ParseNamespacePhase returns the phase named s, case insensitive
+func ParseNamespacePhase(s string) (NamespacePhase, error) {
+	names := make([]string, len(NamespacePhases))
+	for i, phase := range NamespacePhases {
+		if strings.EqualFold(string(phase), s) {
+			return phase, nil
+		}
+		names[i] = string(phase)
+	}
+	return "", fmt.Errorf("Invalid phase '%s'. Use one of %s", s, strings.Join(names, ", "))
+}
+
+// Namespace status, the state a namespace is requested to be in
+const (
+	NamespaceStatusActive   = "ACTIVE"
+	NamespaceStatusPause    = "PAUSE"
+	NamespaceStatusInactive = "INACTIVE"
+)
+
 type StaroidNamespace struct {
-	ID        int64  `json:"id"`
-	Namespace string `json:"name"`
-	Alias     string `json:"instanceName"`
-	Type      string `json:"type"`
-	Phase     string `json:"phase"`
-	Status    string `json:"status"`
-	Access    string `json:"access"`
-	URL       string `json:"url"`
+	ID        int64          `json:"id"`
+	Namespace string         `json:"name"`
+	Alias     string         `json:"instanceName"`
+	Type      string         `json:"type"`
+	Phase     NamespacePhase `json:"phase"`
+	Status    string         `json:"status"`
+	Access    string         `json:"access"`
+	URL       string         `json:"url"`
+	// Commit is the project commit the namespace is created from. nil when unknown
+	Commit *Commit `json:"commit,omitempty"`
 }
 
 func (n *StaroidNamespace) ServiceURL(serviceName string, port int) string {
@@ -42,6 +91,14 @@ type StaroidOrg struct {
 	ID       int64  `json:"id"`
 }
 
+// StaroidUser is the user an access token belongs to
+type StaroidUser struct {
+	ID       int64  `json:"id"`
+	Provider string `json:"provider"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+}
+
 type StaroidNamespaceResources struct {
 	Services v1.ServiceList `json:"services"`
 }
@@ -54,6 +111,16 @@ type Commit struct {
 	Commit   string `json:"commit"`
 }
 
+// String returns commit location in Provider/Owner/Repo:Branch(#Commit) format.
+// See NewCommitFromCommitLocation
+func (c *Commit) String() string {
+	loc := fmt.Sprintf("%s/%s/%s:%s", c.Provider, c.Owner, c.Repo, c.Branch)
+	if c.Commit != "" {
+		loc = fmt.Sprintf("%s#%s", loc, c.Commit)
+	}
+	return loc
+}
+
 // commitLoc format is [Provider]/[]
 func NewCommitFromCommitLocation(commitLoc string) (*Commit, error) {
 	commitHashPos := strings.Index(commitLoc, "#")
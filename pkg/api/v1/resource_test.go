@@ -29,7 +29,17 @@ func TestNewCommitFromCommitLocation(t *testing.T) {
 			assert.Equal(t, testData.parsed[2], commit.Repo)
 			assert.Equal(t, testData.parsed[3], commit.Branch)
 			assert.Equal(t, testData.parsed[4], commit.Commit)
+			assert.Equal(t, testData.flag, commit.String())
 		}
 		assert.Equal(t, testData.parsed[5], strconv.FormatBool(err != nil))
 	}
 }
+
+func TestParseNamespacePhase(t *testing.T) {
+	phase, err := ParseNamespacePhase("running")
+	assert.Nil(t, err)
+	assert.Equal(t, NamespacePhaseRunning, phase)
+
+	_, err = ParseNamespacePhase("UP")
+	assert.EqualError(t, err, "Invalid phase 'UP'. Use one of SCHEDULED, STARTING, RUNNING, PAUSED, REMOVED")
+}
@@ -0,0 +1,124 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SkeRequestBuilder gets Staroid Kubernetes Engines clusters can be created on
+type SkeRequestBuilder struct {
+	v1     *V1
+	ctx    context.Context
+	Cloud  string
+	Region string
+	// AvailableOnly filters out SKEs not available for new clusters
+	AvailableOnly bool
+}
+
+// WithContext sets the context used by requests made from this builder.
+func (b *SkeRequestBuilder) WithContext(ctx context.Context) *SkeRequestBuilder {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	b.ctx = ctx
+	return b
+}
+
+// WithCloud filters SKEs by cloud (e.g. aws). Case insensitive
+func (b *SkeRequestBuilder) WithCloud(cloud string) *SkeRequestBuilder {
+	b.Cloud = cloud
+	return b
+}
+
+// WithRegion filters SKEs by region (e.g. us-west2). Case insensitive
+func (b *SkeRequestBuilder) WithRegion(region string) *SkeRequestBuilder {
+	b.Region = region
+	return b
+}
+
+func (b *SkeRequestBuilder) WithAvailableOnly(availableOnly bool) *SkeRequestBuilder {
+	b.AvailableOnly = availableOnly
+	return b
+}
+
+func (b *SkeRequestBuilder) match(ske *StaroidSke) bool {
+	if b.Cloud != "" && !strings.EqualFold(b.Cloud, ske.Cloud) {
+		return false
+	}
+	if b.Region != "" && !strings.EqualFold(b.Region, ske.Region) {
+		return false
+	}
+	return !b.AvailableOnly || ske.IsAvailable()
+}
+
+// GetAll returns SKEs matching the filters
+func (b *SkeRequestBuilder) GetAll() (*[]StaroidSke, error) {
+	client := b.v1.HttpClient()
+	req, err := b.v1.NewRequestWithContext(b.ctx, "GET", "/ske", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	err = GetApiErrorFromResponse(resp, map[int]string{})
+	if err != nil {
+		return nil, err
+	}
+
+	// parse json response
+	skes := make([]StaroidSke, 0)
+	decoder := json.NewDecoder(resp.Body)
+	err = decoder.Decode(&skes)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]StaroidSke, 0, len(skes))
+	for _, ske := range skes {
+		if b.match(&ske) {
+			filtered = append(filtered, ske)
+		}
+	}
+	return &filtered, nil
+}
+
+// Find returns the SKE of cloud and region. Returns an error when it does not exist or is not available
+func (b *SkeRequestBuilder) Find(cloud string, region string) (*StaroidSke, error) {
+	// filters do not apply
+	builder := SkeRequestBuilder{v1: b.v1, ctx: b.ctx}
+	all, err := builder.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var found *StaroidSke
+	regions := make([]string, 0)
+	for i, ske := range *all {
+		if !strings.EqualFold(ske.Cloud, cloud) {
+			continue
+		}
+		if strings.EqualFold(ske.Region, region) {
+			found = &(*all)[i]
+		} else if ske.IsAvailable() {
+			regions = append(regions, ske.Region)
+		}
+	}
+
+	if found == nil {
+		message := fmt.Sprintf("SKE %s/%s not found", cloud, region)
+		if len(regions) > 0 {
+			message = fmt.Sprintf("%s. Available regions of %s: %s", message, cloud, strings.Join(regions, ", "))
+		}
+		return nil, b.v1.notFoundError("GET", "/ske", message)
+	}
+	if !found.IsAvailable() {
+		return nil, fmt.Errorf("SKE %s/%s is not available for new clusters", found.Cloud, found.Region)
+	}
+	return found, nil
+}
@@ -0,0 +1,70 @@
+package v1_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/staroids/starctl/pkg/api/fake"
+	v1 "github.com/staroids/starctl/pkg/api/v1"
+	"github.com/staroids/starctl/pkg/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkeGetAll(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	os.Setenv(constants.EnvStaroidApiServer, server.URL)
+	defer os.Unsetenv(constants.EnvStaroidApiServer)
+	server.AddSke("aws", "eu-west1", true)
+
+	tests := []struct {
+		name      string
+		cloud     string
+		region    string
+		available bool
+		expected  int
+	}{
+		{"all", "", "", false, 4},
+		{"cloud", "AWS", "", false, 2},
+		{"region", "", "eastus", false, 1},
+		{"available", "", "", true, 3},
+		{"no match", "aws", "eastus", false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skes, err := (&v1.V1{}).Ske().WithCloud(tt.cloud).WithRegion(tt.region).WithAvailableOnly(tt.available).GetAll()
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, len(*skes))
+		})
+	}
+}
+
+func TestSkeFind(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	os.Setenv(constants.EnvStaroidApiServer, server.URL)
+	defer os.Unsetenv(constants.EnvStaroidApiServer)
+
+	ske, err := (&v1.V1{}).Ske().Find("aws", "us-west2")
+	assert.Nil(t, err)
+	assert.Equal(t, "aws us-west2", ske.ID)
+
+	_, err = (&v1.V1{}).Ske().Find("aws", "us-east9")
+	assert.True(t, v1.IsNotFound(err))
+	assert.Contains(t, err.Error(), "Available regions of aws: us-west2 (GET /ske)")
+
+	_, err = (&v1.V1{}).Ske().Find("azure", "eastus")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "not available")
+
+	// missing 'available' is not taken as unavailable
+	server.AddSkeWithoutAvailability("gcp", "europe-west1")
+	ske, err = (&v1.V1{}).Ske().Find("gcp", "europe-west1")
+	assert.Nil(t, err)
+	assert.Nil(t, ske.Available)
+
+	skes, err := (&v1.V1{}).Ske().WithCloud("gcp").WithAvailableOnly(true).GetAll()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(*skes))
+}
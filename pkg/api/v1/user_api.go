@@ -0,0 +1,49 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+)
+
+type UserRequestBuilder struct {
+	v1  *V1
+	ctx context.Context
+}
+
+// WithContext sets the context used by requests made from this builder.
+func (b *UserRequestBuilder) WithContext(ctx context.Context) *UserRequestBuilder {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	b.ctx = ctx
+	return b
+}
+
+// Get returns the user the access token belongs to
+func (b *UserRequestBuilder) Get() (*StaroidUser, error) {
+	client := b.v1.HttpClient()
+	req, err := b.v1.NewRequestWithContext(b.ctx, "GET", "/user", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	err = GetApiErrorFromResponse(resp, map[int]string{})
+	if err != nil {
+		return nil, err
+	}
+
+	// parse json response
+	user := StaroidUser{}
+	decoder := json.NewDecoder(resp.Body)
+	err = decoder.Decode(&user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
@@ -1,32 +1,60 @@
 package v1
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
+	"sync"
 
+	"github.com/staroids/starctl/pkg/api/transport"
 	"github.com/staroids/starctl/pkg/auth"
 )
 
 type V1 struct {
 	Auth auth.StaroidAuth
+
+	// Client is used for all requests when set. Otherwise a client retrying with Retry policy is created
+	Client *http.Client
+	Retry  transport.RetryPolicy
+
+	once       sync.Once
+	httpClient *http.Client
 }
 
 func (v *V1) Cluster() *ClusterRequestBuilder {
 	return &ClusterRequestBuilder{
-		v1: v,
+		v1:  v,
+		ctx: context.Background(),
 	}
 }
 
 func (v *V1) Namespace() *NamespaceRequestBuilder {
 	return &NamespaceRequestBuilder{
-		v1: v,
+		v1:  v,
+		ctx: context.Background(),
 	}
 }
 
 func (v *V1) Org() *OrgRequestBuilder {
 	return &OrgRequestBuilder{
-		v1: v,
+		v1:  v,
+		ctx: context.Background(),
+	}
+}
+
+func (v *V1) Ske() *SkeRequestBuilder {
+	return &SkeRequestBuilder{
+		v1:  v,
+		ctx: context.Background(),
+	}
+}
+
+func (v *V1) User() *UserRequestBuilder {
+	return &UserRequestBuilder{
+		v1:  v,
+		ctx: context.Background(),
 	}
 }
 
@@ -47,37 +75,55 @@ func (v *V1) NewPutRequest(path string, body io.Reader) (*http.Request, error) {
 }
 
 func (v *V1) NewRequest(method string, path string, body io.Reader) (*http.Request, error) {
+	return v.NewRequestWithContext(context.Background(), method, path, body)
+}
+
+// NewRequestWithContext creates an authenticated api request bound to ctx.
+// Cancelling ctx aborts the request. Returns error of the token source, instead of sending the request without token.
+func (v *V1) NewRequestWithContext(ctx context.Context, method string, path string, body io.Reader) (*http.Request, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	token, err := v.Auth.GetAccessToken()
+	if err != nil {
+		return nil, err
+	}
 	url := fmt.Sprintf("%s%s", v.Auth.ApiServer(), path)
-	req, err := http.NewRequest(method, url, body)
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("token %s", v.Auth.AccessToken()))
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", token))
 	req.Header.Set("Content-Type", "application/json")
 	return req, nil
 }
 
-func (v *V1) HttpClient() *http.Client {
-	return &http.Client{}
-}
-
-func GetApiErrorFromResponse(resp *http.Response, customErrorMessage map[int]string) error {
-	message := map[int]string{
-		404: "Not found",
-		402: "Not authorized",
+// notFoundError returns a 404 error for a resource missing from the list returned by the request to path.
+// Method and Path are filled as GetApiErrorFromResponse does for the request.
+func (v *V1) notFoundError(method string, path string, message string) *StaroidAPIError {
+	apiErr := &StaroidAPIError{
+		StatusCode: http.StatusNotFound,
+		Message:    message,
+		Method:     method,
+		Path:       path,
 	}
-
-	for k, v := range customErrorMessage {
-		message[k] = v
-	}
-
-	if resp.StatusCode == 200 {
-		return nil
+	if u, err := neturl.Parse(fmt.Sprintf("%s%s", v.Auth.ApiServer(), path)); err == nil {
+		apiErr.Path = u.Path
 	}
+	return apiErr
+}
 
-	if m, ok := message[resp.StatusCode]; ok {
-		return fmt.Errorf("%d %s", resp.StatusCode, m)
+// HttpClient returns Client, or a client shared by all requests of this V1 when Client is not set.
+// The shared client is built on first call; changing Retry afterwards has no effect.
+func (v *V1) HttpClient() *http.Client {
+	if v.Client != nil {
+		return v.Client
 	}
-	return fmt.Errorf("%d", resp.StatusCode)
+	v.once.Do(func() {
+		v.httpClient = &http.Client{
+			Transport: transport.NewRetryTransport(http.DefaultTransport, v.Retry),
+		}
+	})
+	return v.httpClient
 }
@@ -0,0 +1,35 @@
+package v1_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/staroids/starctl/pkg/api/fake"
+	v1 "github.com/staroids/starctl/pkg/api/v1"
+	"github.com/staroids/starctl/pkg/auth"
+	"github.com/staroids/starctl/pkg/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRequestTokenError(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	os.Setenv(constants.EnvStaroidApiServer, server.URL)
+	defer os.Unsetenv(constants.EnvStaroidApiServer)
+
+	client := &v1.V1{
+		Auth: auth.StaroidAuth{
+			TokenSource: &auth.FileTokenSource{Path: filepath.Join(os.TempDir(), "starctl-no-such-token")},
+		},
+	}
+	_, err := client.Org().GetAll()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Can't read token file")
+	assert.Equal(t, 0, len(server.Requests()))
+}
+
+func TestHttpClientShared(t *testing.T) {
+	client := &v1.V1{}
+	assert.Same(t, client.HttpClient(), client.HttpClient())
+}
@@ -2,36 +2,94 @@ package auth
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
+	"strings"
 
+	"github.com/staroids/starctl/pkg/config"
 	"github.com/staroids/starctl/pkg/constants"
 )
 
-// StaroidAuth handles authentication
+// StaroidAuth handles authentication.
+// Access token is read from Token, or TokenSource. Without TokenSource, NewDefaultTokenSource() of
+// ProfileName, Profile and Credentials is used.
 type StaroidAuth struct {
-	accessToken string
-	apiServer   string
+	// Token is the access token to use. Optional
+	Token string
+	// TokenSource provides access token when Token is empty. Optional
+	TokenSource TokenSource
+	// Credentials provides access token of ProfileName. Optional
+	Credentials *CredentialStore
+	// ProfileName is the name of Profile
+	ProfileName string
+	// Profile provides access token and api server when environment variables are not set. Optional
+	Profile *config.Profile
+}
+
+func (s *StaroidAuth) tokenSource() TokenSource {
+	if s.Token != "" {
+		return StaticTokenSource(s.Token)
+	}
+	if s.TokenSource != nil {
+		return s.TokenSource
+	}
+	return NewDefaultTokenSource(s.ProfileName, s.Profile, s.Credentials)
 }
 
 // CheckAuth checks authentication
 func (s *StaroidAuth) CheckAuth() error {
-	token := s.AccessToken()
+	token, err := s.tokenSource().Token()
+	if err != nil {
+		return err
+	}
 	if token == "" {
-		return fmt.Errorf("Please set %s environment variable.", constants.EnvStaroidAccessToken)
+		return fmt.Errorf("Please run 'starctl login' or set %s environment variable.", constants.EnvStaroidAccessToken)
 	}
 	return nil
 }
 
-// AccessToken returns Access Token
+// GetAccessToken returns Access Token, or error of the token source (e.g. failed token-command)
+func (s *StaroidAuth) GetAccessToken() (string, error) {
+	token, err := s.tokenSource().Token()
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// AccessToken returns Access Token. Empty when the token source fails. See GetAccessToken
 func (s *StaroidAuth) AccessToken() string {
-	s.accessToken = os.Getenv(constants.EnvStaroidAccessToken)
-	return s.accessToken
+	token, _ := s.GetAccessToken()
+	return token
+}
+
+// Source describes where the access token is read from. Empty when no token is found
+func (s *StaroidAuth) Source() string {
+	source := s.tokenSource()
+	if chain, ok := source.(ChainTokenSource); ok {
+		token, from, _ := chain.TokenWithSource()
+		if token == "" {
+			return ""
+		}
+		return describe(from)
+	}
+	return describe(source)
 }
 
 func (s *StaroidAuth) ApiServer() string {
-	s.apiServer = os.Getenv(constants.EnvStaroidApiServer)
-	if s.apiServer == "" {
-		s.apiServer = constants.ApiServer
+	if apiServer := os.Getenv(constants.EnvStaroidApiServer); apiServer != "" {
+		return apiServer
+	}
+	if s.Profile != nil && s.Profile.ApiServer != "" {
+		return s.Profile.ApiServer
+	}
+	return constants.ApiServer
+}
+
+func readTokenFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("Can't read token file: %v", err)
 	}
-	return s.apiServer
+	return strings.TrimSpace(string(data)), nil
 }
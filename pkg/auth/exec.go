@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// execTimeout limits how long a credential helper may run
+	execTimeout = 30 * time.Second
+	// execExpirySkew renews a token this long before it expires
+	execExpirySkew = 30 * time.Second
+)
+
+// ExecCredential is the JSON a credential helper prints to stdout.
+//
+//	{"token": "xxxxxxxx", "expiresAt": "2020-07-01T10:00:00Z"}
+//
+// expiresAt is optional. Without it the token is used until starctl exits.
+type ExecCredential struct {
+	Token     string     `json:"token"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// ExecProvider gets access token by running a credential helper command through the shell,
+// and caches it until it expires.
+type ExecProvider struct {
+	Command string
+
+	mu         sync.Mutex
+	credential *ExecCredential
+	now        func() time.Time
+}
+
+var (
+	execProvidersMu sync.Mutex
+	execProviders   = make(map[string]*ExecProvider)
+)
+
+// NewExecProvider returns provider running command.
+// Providers are shared by command, so the token is cached across copies of StaroidAuth
+func NewExecProvider(command string) *ExecProvider {
+	execProvidersMu.Lock()
+	defer execProvidersMu.Unlock()
+
+	p, ok := execProviders[command]
+	if !ok {
+		p = &ExecProvider{Command: command, now: time.Now}
+		execProviders[command] = p
+	}
+	return p
+}
+
+// Token returns cached access token, or runs the command when there's none or it's about to expire
+func (p *ExecProvider) Token() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.credential != nil && !p.expired(p.credential) {
+		return p.credential.Token, nil
+	}
+
+	credential, err := p.run()
+	if err != nil {
+		return "", err
+	}
+	p.credential = credential
+	return credential.Token, nil
+}
+
+func (p *ExecProvider) expired(c *ExecCredential) bool {
+	return c.ExpiresAt != nil && !p.now().Add(execExpirySkew).Before(*c.ExpiresAt)
+}
+
+func (p *ExecProvider) run() (*ExecCredential, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), execTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", p.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", p.Command)
+	}
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg != "" {
+			return nil, fmt.Errorf("Token command '%s' failed: %v: %s", p.Command, err, msg)
+		}
+		return nil, fmt.Errorf("Token command '%s' failed: %v", p.Command, err)
+	}
+
+	credential := ExecCredential{}
+	if err := json.Unmarshal(stdout.Bytes(), &credential); err != nil {
+		return nil, fmt.Errorf("Token command '%s' printed invalid JSON: %v", p.Command, err)
+	}
+	if credential.Token == "" {
+		return nil, fmt.Errorf("Token command '%s' returned no token", p.Command)
+	}
+	if p.expired(&credential) {
+		return nil, fmt.Errorf("Token command '%s' returned an expired token", p.Command)
+	}
+	return &credential, nil
+}
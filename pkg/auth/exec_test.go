@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/staroids/starctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingCommand returns a command printing output and counting its runs in a file
+func countingCommand(t *testing.T, output string) (string, func() int) {
+	dir, err := ioutil.TempDir("", "starctl-exec")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	counter := filepath.Join(dir, "count")
+	command := fmt.Sprintf("echo x >> %s; echo '%s'", counter, output)
+	return command, func() int {
+		data, _ := ioutil.ReadFile(counter)
+		return strings.Count(string(data), "x")
+	}
+}
+
+func TestExecProvider(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test commands require sh")
+	}
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	command, runs := countingCommand(t, `{"token": "exec-token", "expiresAt": "`+expiresAt+`"}`)
+
+	p := NewExecProvider(command)
+	assert.Same(t, p, NewExecProvider(command))
+
+	token, err := p.Token()
+	assert.Nil(t, err)
+	assert.Equal(t, "exec-token", token)
+	token, err = p.Token()
+	assert.Nil(t, err)
+	assert.Equal(t, "exec-token", token)
+	assert.Equal(t, 1, runs())
+
+	// renew after expiry
+	p.now = func() time.Time { return time.Now().Add(time.Hour) }
+	_, err = p.Token()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "expired")
+	assert.Equal(t, 2, runs())
+}
+
+func TestExecProviderErrors(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test commands require sh")
+	}
+
+	tests := []struct {
+		command string
+		message string
+	}{
+		{"echo 'vault sealed' >&2; exit 3", "vault sealed"},
+		{"echo not-json", "invalid JSON"},
+		{`echo '{"expiresAt": "2020-01-01T00:00:00Z"}'`, "no token"},
+	}
+
+	for _, tt := range tests {
+		_, err := NewExecProvider(tt.command).Token()
+		assert.NotNil(t, err, tt.command)
+		assert.Contains(t, err.Error(), tt.message)
+	}
+}
+
+func TestStaroidAuthTokenCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test commands require sh")
+	}
+
+	os.Unsetenv("STAROID_ACCESS_TOKEN")
+	command, runs := countingCommand(t, `{"token": "exec-token"}`)
+	a := StaroidAuth{
+		ProfileName: "ci",
+		Profile:     &config.Profile{TokenCommand: command, AccessToken: "plain-token"},
+	}
+	assert.Nil(t, a.CheckAuth())
+	assert.Equal(t, "exec-token", a.AccessToken())
+	assert.Equal(t, "token-command of profile 'ci'", a.Source())
+
+	// copies share the cached token
+	copied := StaroidAuth{Profile: a.Profile}
+	assert.Equal(t, "exec-token", copied.AccessToken())
+	assert.Equal(t, 1, runs())
+}
@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+)
+
+// LoginFlow receives an access token through the browser.
+//
+// It starts a callback server on the loopback interface and opens
+// AuthURL?redirect_uri=http://127.0.0.1:<port>/callback&state=<state>.
+// After the user signs in, the auth page redirects to redirect_uri with 'token' and 'state' query parameters.
+type LoginFlow struct {
+	AuthURL string
+	// OpenBrowser opens url in a browser. The url is only printed when nil
+	OpenBrowser func(url string) error
+	// Out is where instructions are printed. ioutil.Discard when nil
+	Out io.Writer
+}
+
+type loginResult struct {
+	token string
+	err   error
+}
+
+// Run executes the flow and returns received access token. Cancel ctx to give up waiting.
+func (f *LoginFlow) Run(ctx context.Context) (string, error) {
+	out := f.Out
+	if out == nil {
+		out = ioutil.Discard
+	}
+
+	state, err := randomState()
+	if err != nil {
+		return "", err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	redirectURI := fmt.Sprintf("http://%s/callback", listener.Addr().String())
+
+	loginURL, err := url.Parse(f.AuthURL)
+	if err != nil {
+		listener.Close()
+		return "", fmt.Errorf("Invalid auth url: %v", err)
+	}
+	query := loginURL.Query()
+	query.Set("redirect_uri", redirectURI)
+	query.Set("state", state)
+	loginURL.RawQuery = query.Encode()
+
+	result := make(chan loginResult, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("state") != state {
+			http.Error(w, "Invalid state. Please retry 'starctl login'.", http.StatusBadRequest)
+			return
+		}
+
+		res := loginResult{token: q.Get("token")}
+		if e := q.Get("error"); e != "" {
+			res.err = fmt.Errorf("Login failed: %s", e)
+		} else if res.token == "" {
+			res.err = fmt.Errorf("Login failed: no token received")
+		}
+
+		if res.err != nil {
+			http.Error(w, res.err.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprintf(w, "Login successful. You can close this window and return to starctl.\n")
+		}
+		select {
+		case result <- res:
+		default:
+		}
+	})
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Close()
+
+	fmt.Fprintf(out, "Open the following url in a browser to log in.\n\n  %s\n\n", loginURL.String())
+	if f.OpenBrowser != nil {
+		if err := f.OpenBrowser(loginURL.String()); err != nil {
+			fmt.Fprintf(out, "Can't open browser: %v\n", err)
+		}
+	}
+
+	select {
+	case res := <-result:
+		return res.token, res.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func randomState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// OpenBrowser opens url in the default browser of the platform
+func OpenBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newAuthServer is a stand-in auth page that immediately redirects back with the token
+func newAuthServer(token string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirect, _ := url.Parse(r.URL.Query().Get("redirect_uri"))
+		q := redirect.Query()
+		q.Set("state", r.URL.Query().Get("state"))
+		q.Set("token", token)
+		redirect.RawQuery = q.Encode()
+		http.Redirect(w, r, redirect.String(), http.StatusFound)
+	}))
+}
+
+func browse(u string) error {
+	go func() {
+		resp, err := http.Get(u)
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	return nil
+}
+
+func TestLoginFlow(t *testing.T) {
+	authServer := newAuthServer("new-token")
+	defer authServer.Close()
+
+	flow := &LoginFlow{AuthURL: authServer.URL + "/cli/login", OpenBrowser: browse}
+	token, err := flow.Run(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "new-token", token)
+}
+
+func TestLoginFlowRejectsInvalidState(t *testing.T) {
+	var loginURL string
+	flow := &LoginFlow{AuthURL: "http://auth.example.com/cli/login", OpenBrowser: func(u string) error {
+		loginURL = u
+		parsed, _ := url.Parse(u)
+		redirect := parsed.Query().Get("redirect_uri") + "?state=forged&token=stolen"
+		resp, err := http.Get(redirect)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		resp.Body.Close()
+		return nil
+	}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := flow.Run(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Contains(t, loginURL, "http://auth.example.com/cli/login?redirect_uri=http%3A%2F%2F127.0.0.1")
+}
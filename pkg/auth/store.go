@@ -0,0 +1,255 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+
+	"github.com/staroids/starctl/pkg/constants"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	credentialFileName = "credentials"
+	keyFileName        = "credentials.key"
+
+	// key derivation
+	kdfPassphrase = "passphrase"
+	kdfKeyFile    = "keyfile"
+	scryptN       = 1 << 15
+	scryptR       = 8
+	scryptP       = 1
+	keySize       = 32
+)
+
+// encryptedFile is the on-disk format of the credential store
+type encryptedFile struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// CredentialStore keeps access tokens of profiles in a file encrypted with AES-GCM.
+// The key is derived from Passphrase when set, otherwise from a random key file created next to the store.
+// Files readable by group or others are refused.
+type CredentialStore struct {
+	Dir        string
+	Passphrase string
+
+	tokens map[string]string
+}
+
+func NewCredentialStore(dir string, passphrase string) *CredentialStore {
+	return &CredentialStore{
+		Dir:        dir,
+		Passphrase: passphrase,
+	}
+}
+
+func (s *CredentialStore) path() string {
+	return filepath.Join(s.Dir, credentialFileName)
+}
+
+func (s *CredentialStore) keyFilePath() string {
+	return filepath.Join(s.Dir, keyFileName)
+}
+
+// Get returns access token of the profile. Empty string when not stored.
+func (s *CredentialStore) Get(profile string) (string, error) {
+	if err := s.load(); err != nil {
+		return "", err
+	}
+	return s.tokens[profile], nil
+}
+
+// Set stores access token of the profile
+func (s *CredentialStore) Set(profile string, token string) error {
+	if err := s.load(); err != nil {
+		return err
+	}
+	s.tokens[profile] = token
+	return s.save()
+}
+
+// Remove deletes access token of the profile. Returns false if it was not stored.
+func (s *CredentialStore) Remove(profile string) (bool, error) {
+	if err := s.load(); err != nil {
+		return false, err
+	}
+	if _, ok := s.tokens[profile]; !ok {
+		return false, nil
+	}
+	delete(s.tokens, profile)
+	return true, s.save()
+}
+
+// Profiles returns sorted names of profiles that have an access token stored
+func (s *CredentialStore) Profiles() ([]string, error) {
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(s.tokens))
+	for name := range s.tokens {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// checkPermission refuses files accessible by group or others
+func checkPermission(path string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		return fmt.Errorf("%s is accessible by other users (mode %04o). Run 'chmod 600 %s'", path, info.Mode().Perm(), path)
+	}
+	return nil
+}
+
+func (s *CredentialStore) kdf() string {
+	if s.Passphrase != "" {
+		return kdfPassphrase
+	}
+	return kdfKeyFile
+}
+
+// secret returns material the key is derived from
+func (s *CredentialStore) secret(kdf string, create bool) ([]byte, error) {
+	if kdf == kdfPassphrase {
+		if s.Passphrase == "" {
+			return nil, fmt.Errorf("Credential store is protected by a passphrase. Please set %s environment variable", constants.EnvStarctlCredentialPassphrase)
+		}
+		return []byte(s.Passphrase), nil
+	}
+
+	path := s.keyFilePath()
+	key, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && create {
+		key = make([]byte, keySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(s.Dir, 0700); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(path, key, 0600); err != nil {
+			return nil, err
+		}
+		return key, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("Can't read credential key file: %v", err)
+	}
+	if err := checkPermission(path); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func newGCM(secret []byte, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(secret, salt, scryptN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *CredentialStore) load() error {
+	if s.tokens != nil {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(s.path())
+	if os.IsNotExist(err) {
+		s.tokens = make(map[string]string)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := checkPermission(s.path()); err != nil {
+		return err
+	}
+
+	f := encryptedFile{}
+	if err := json.Unmarshal(data, &f); err != nil || f.Version != 1 {
+		return fmt.Errorf("Invalid credential file %s", s.path())
+	}
+	secret, err := s.secret(f.KDF, false)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(secret, f.Salt)
+	if err != nil {
+		return err
+	}
+	plain, err := gcm.Open(nil, f.Nonce, f.Data, nil)
+	if err != nil {
+		return fmt.Errorf("Can't decrypt credential file %s. Wrong passphrase or key file", s.path())
+	}
+
+	tokens := make(map[string]string)
+	if err := json.Unmarshal(plain, &tokens); err != nil {
+		return fmt.Errorf("Invalid credential file %s", s.path())
+	}
+	s.tokens = tokens
+	return nil
+}
+
+func (s *CredentialStore) save() error {
+	plain, err := json.Marshal(s.tokens)
+	if err != nil {
+		return err
+	}
+
+	f := encryptedFile{
+		Version: 1,
+		KDF:     s.kdf(),
+		Salt:    make([]byte, 16),
+	}
+	if _, err := rand.Read(f.Salt); err != nil {
+		return err
+	}
+	secret, err := s.secret(f.KDF, true)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(secret, f.Salt)
+	if err != nil {
+		return err
+	}
+	f.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return err
+	}
+	f.Data = gcm.Seal(nil, f.Nonce, plain, nil)
+
+	data, err := json.Marshal(&f)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0700); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of an existing file, so fix it explicitly
+	if err := ioutil.WriteFile(s.path(), data, 0600); err != nil {
+		return err
+	}
+	return os.Chmod(s.path(), 0600)
+}
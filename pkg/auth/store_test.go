@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCredentialStore(t *testing.T) {
+	tests := []struct {
+		name       string
+		passphrase string
+	}{
+		{"keyfile", ""},
+		{"passphrase", "secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "starctl-store")
+			assert.Nil(t, err)
+			defer os.RemoveAll(dir)
+
+			s := NewCredentialStore(dir, tt.passphrase)
+			assert.Nil(t, s.Set("default", "token-1"))
+			assert.Nil(t, s.Set("dev", "token-2"))
+
+			data, err := ioutil.ReadFile(filepath.Join(dir, credentialFileName))
+			assert.Nil(t, err)
+			assert.False(t, strings.Contains(string(data), "token-1"))
+
+			// read back with a new store
+			s = NewCredentialStore(dir, tt.passphrase)
+			token, err := s.Get("dev")
+			assert.Nil(t, err)
+			assert.Equal(t, "token-2", token)
+
+			removed, err := s.Remove("dev")
+			assert.Nil(t, err)
+			assert.True(t, removed)
+			removed, err = s.Remove("dev")
+			assert.Nil(t, err)
+			assert.False(t, removed)
+
+			profiles, err := NewCredentialStore(dir, tt.passphrase).Profiles()
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"default"}, profiles)
+		})
+	}
+}
+
+func TestCredentialStoreWrongPassphrase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "starctl-store")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.Nil(t, NewCredentialStore(dir, "secret").Set("default", "token"))
+
+	_, err = NewCredentialStore(dir, "wrong").Get("default")
+	assert.NotNil(t, err)
+	_, err = NewCredentialStore(dir, "").Get("default")
+	assert.NotNil(t, err)
+}
+
+func TestCredentialStorePermission(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file mode is not checked on windows")
+	}
+
+	dir, err := ioutil.TempDir("", "starctl-store")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.Nil(t, NewCredentialStore(dir, "").Set("default", "token"))
+	assert.Nil(t, os.Chmod(filepath.Join(dir, credentialFileName), 0644))
+
+	_, err = NewCredentialStore(dir, "").Get("default")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "chmod 600")
+
+	// key file is checked too
+	assert.Nil(t, os.Chmod(filepath.Join(dir, credentialFileName), 0600))
+	assert.Nil(t, os.Chmod(filepath.Join(dir, keyFileName), 0604))
+	_, err = NewCredentialStore(dir, "").Get("default")
+	assert.NotNil(t, err)
+}
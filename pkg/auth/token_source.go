@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/staroids/starctl/pkg/config"
+	"github.com/staroids/starctl/pkg/constants"
+)
+
+// TokenSource provides access token
+type TokenSource interface {
+	// Token returns access token. Returns empty string without error when the source has no token
+	Token() (string, error)
+}
+
+// StaticTokenSource always returns the same token
+type StaticTokenSource string
+
+func (s StaticTokenSource) Token() (string, error) {
+	return string(s), nil
+}
+
+func (s StaticTokenSource) String() string {
+	return "token"
+}
+
+// EnvTokenSource reads access token from environment variable Name
+type EnvTokenSource struct {
+	Name string
+}
+
+func (s *EnvTokenSource) Token() (string, error) {
+	return os.Getenv(s.Name), nil
+}
+
+func (s *EnvTokenSource) String() string {
+	return "env " + s.Name
+}
+
+// FileTokenSource reads access token from file Path
+type FileTokenSource struct {
+	Path string
+}
+
+func (s *FileTokenSource) Token() (string, error) {
+	return readTokenFile(s.Path)
+}
+
+func (s *FileTokenSource) String() string {
+	return "token file " + s.Path
+}
+
+// ProfileTokenSource reads access-token, or token-file of a profile
+type ProfileTokenSource struct {
+	Name    string
+	Profile *config.Profile
+}
+
+func (s *ProfileTokenSource) Token() (string, error) {
+	if s.Profile == nil {
+		return "", nil
+	}
+	if s.Profile.AccessToken != "" {
+		return s.Profile.AccessToken, nil
+	}
+	if s.Profile.TokenFile != "" {
+		return readTokenFile(s.Profile.TokenFile)
+	}
+	return "", nil
+}
+
+func (s *ProfileTokenSource) String() string {
+	if s.Profile != nil && s.Profile.AccessToken == "" && s.Profile.TokenFile != "" {
+		return fmt.Sprintf("token-file of profile '%s'", s.Name)
+	}
+	return fmt.Sprintf("access-token of profile '%s'", s.Name)
+}
+
+// CredentialStoreTokenSource reads access token of Profile from Store
+type CredentialStoreTokenSource struct {
+	Store   *CredentialStore
+	Profile string
+}
+
+func (s *CredentialStoreTokenSource) Token() (string, error) {
+	return s.Store.Get(s.Profile)
+}
+
+func (s *CredentialStoreTokenSource) String() string {
+	return fmt.Sprintf("credential store (profile '%s')", s.Profile)
+}
+
+// namedTokenSource gives a TokenSource a description
+type namedTokenSource struct {
+	TokenSource
+	name string
+}
+
+func (s *namedTokenSource) String() string {
+	return s.name
+}
+
+// ChainTokenSource returns token of the first source that has one.
+// An error of a source stops the chain.
+type ChainTokenSource []TokenSource
+
+func (c ChainTokenSource) Token() (string, error) {
+	token, _, err := c.TokenWithSource()
+	return token, err
+}
+
+// TokenWithSource returns the token and the source it's read from
+func (c ChainTokenSource) TokenWithSource() (string, TokenSource, error) {
+	for _, source := range c {
+		token, err := source.Token()
+		if err != nil {
+			return "", source, err
+		}
+		if token != "" {
+			return token, source, nil
+		}
+	}
+	return "", nil, nil
+}
+
+// NewDefaultTokenSource returns the chain starctl uses, in order of precedence:
+// STAROID_ACCESS_TOKEN, file in STAROID_ACCESS_TOKEN_FILE, token-command of the profile,
+// credential store, and access-token or token-file of the profile.
+// store and profile are optional.
+func NewDefaultTokenSource(profileName string, profile *config.Profile, store *CredentialStore) ChainTokenSource {
+	chain := ChainTokenSource{&EnvTokenSource{Name: constants.EnvStaroidAccessToken}}
+	if path := os.Getenv(constants.EnvStaroidAccessTokenFile); path != "" {
+		chain = append(chain, &FileTokenSource{Path: path})
+	}
+	if profile != nil && profile.TokenCommand != "" {
+		chain = append(chain, &namedTokenSource{
+			TokenSource: NewExecProvider(profile.TokenCommand),
+			name:        fmt.Sprintf("token-command of profile '%s'", profileName),
+		})
+	}
+	if store != nil {
+		chain = append(chain, &CredentialStoreTokenSource{Store: store, Profile: profileName})
+	}
+	if profile != nil {
+		chain = append(chain, &ProfileTokenSource{Name: profileName, Profile: profile})
+	}
+	return chain
+}
+
+// describe returns description of the source
+func describe(source TokenSource) string {
+	if source == nil {
+		return ""
+	}
+	if s, ok := source.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", source)
+}
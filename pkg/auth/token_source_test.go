@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/staroids/starctl/pkg/config"
+	"github.com/staroids/starctl/pkg/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultTokenSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "starctl-token")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	tokenFile := filepath.Join(dir, "token")
+	assert.Nil(t, ioutil.WriteFile(tokenFile, []byte("file-token\n"), 0600))
+
+	store := NewCredentialStore(dir, "")
+	assert.Nil(t, store.Set("dev", "stored-token"))
+
+	tests := []struct {
+		name      string
+		env       map[string]string
+		profile   *config.Profile
+		store     *CredentialStore
+		token     string
+		source    string
+		expectErr bool
+	}{
+		{"none", nil, nil, nil, "", "", false},
+		{"env", map[string]string{constants.EnvStaroidAccessToken: "env-token", constants.EnvStaroidAccessTokenFile: tokenFile}, &config.Profile{AccessToken: "profile-token"}, store, "env-token", "env " + constants.EnvStaroidAccessToken, false},
+		{"env file", map[string]string{constants.EnvStaroidAccessTokenFile: tokenFile}, &config.Profile{AccessToken: "profile-token"}, store, "file-token", "token file " + tokenFile, false},
+		{"missing env file", map[string]string{constants.EnvStaroidAccessTokenFile: filepath.Join(dir, "missing")}, nil, store, "", "", true},
+		{"store", nil, &config.Profile{AccessToken: "profile-token"}, store, "stored-token", "credential store (profile 'dev')", false},
+		{"profile", nil, &config.Profile{AccessToken: "profile-token"}, nil, "profile-token", "access-token of profile 'dev'", false},
+		{"profile token file", nil, &config.Profile{TokenFile: tokenFile}, nil, "file-token", "token-file of profile 'dev'", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{constants.EnvStaroidAccessToken, constants.EnvStaroidAccessTokenFile} {
+				os.Unsetenv(name)
+			}
+			for k, v := range tt.env {
+				os.Setenv(k, v)
+				defer os.Unsetenv(k)
+			}
+
+			a := StaroidAuth{ProfileName: "dev", Profile: tt.profile, Credentials: tt.store}
+			err := a.CheckAuth()
+			if tt.expectErr || tt.token == "" {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tt.token, a.AccessToken())
+			assert.Equal(t, tt.source, a.Source())
+		})
+	}
+}
+
+func TestChainTokenSource(t *testing.T) {
+	chain := ChainTokenSource{StaticTokenSource(""), StaticTokenSource("second"), StaticTokenSource("third")}
+	token, source, err := chain.TokenWithSource()
+	assert.Nil(t, err)
+	assert.Equal(t, "second", token)
+	assert.Equal(t, StaticTokenSource("second"), source)
+
+	a := StaroidAuth{TokenSource: chain}
+	assert.Equal(t, "second", a.AccessToken())
+
+	// Token takes precedence over TokenSource
+	a.Token = "explicit"
+	assert.Equal(t, "explicit", a.AccessToken())
+}
@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Cache is a file based key value store with expiry.
+// Methods of nil *Cache are no-op, so a nil Cache can be used to disable caching.
+type Cache struct {
+	Dir string
+	TTL time.Duration
+}
+
+type entry struct {
+	Key     string          `json:"key"`
+	Expires time.Time       `json:"expires"`
+	Value   json.RawMessage `json:"value"`
+}
+
+func New(dir string, ttl time.Duration) *Cache {
+	return &Cache{
+		Dir: dir,
+		TTL: ttl,
+	}
+}
+
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get decodes cached value of key into v. Returns false if not cached or expired.
+func (c *Cache) Get(key string, v interface{}) bool {
+	if c == nil {
+		return false
+	}
+
+	data, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return false
+	}
+	e := entry{}
+	if err := json.Unmarshal(data, &e); err != nil || e.Key != key {
+		return false
+	}
+	if time.Now().After(e.Expires) {
+		os.Remove(c.path(key))
+		return false
+	}
+	return json.Unmarshal(e.Value, v) == nil
+}
+
+// Set stores v under key for TTL
+func (c *Cache) Set(key string, v interface{}) error {
+	if c == nil {
+		return nil
+	}
+
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(&entry{
+		Key:     key,
+		Expires: time.Now().Add(c.TTL),
+		Value:   value,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return err
+	}
+	// write to temp file and rename, so concurrent readers never see partial file
+	tmp, err := ioutil.TempFile(c.Dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+// Delete removes key from the cache
+func (c *Cache) Delete(key string) error {
+	if c == nil {
+		return nil
+	}
+
+	err := os.Remove(c.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Clear removes all cached entries
+func (c *Cache) Clear() error {
+	if c == nil {
+		return nil
+	}
+
+	files, err := ioutil.ReadDir(c.Dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), ".json") || strings.HasPrefix(f.Name(), ".tmp-") {
+			if err := os.Remove(filepath.Join(c.Dir, f.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "starctl-cache")
+	defer os.RemoveAll(dir)
+
+	c := New(dir, time.Minute)
+	value := []string{}
+	assert.False(t, c.Get("orgs", &value))
+
+	assert.Nil(t, c.Set("orgs", []string{"GITHUB/staroids"}))
+	assert.True(t, c.Get("orgs", &value))
+	assert.Equal(t, []string{"GITHUB/staroids"}, value)
+
+	assert.Nil(t, c.Delete("orgs"))
+	assert.False(t, c.Get("orgs", &value))
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	assert.Nil(t, c.Clear())
+	n := 0
+	assert.False(t, c.Get("a", &n))
+	assert.False(t, c.Get("b", &n))
+}
+
+func TestCacheExpiry(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "starctl-cache")
+	defer os.RemoveAll(dir)
+
+	c := New(dir, -time.Second)
+	c.Set("orgs", 1)
+	n := 0
+	assert.False(t, c.Get("orgs", &n))
+}
+
+func TestNilCache(t *testing.T) {
+	var c *Cache
+	n := 0
+	assert.Nil(t, c.Set("a", 1))
+	assert.False(t, c.Get("a", &n))
+	assert.Nil(t, c.Delete("a"))
+	assert.Nil(t, c.Clear())
+}
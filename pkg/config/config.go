@@ -0,0 +1,180 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// DefaultProfileName is used when no profile is selected
+const DefaultProfileName = "default"
+
+// Profile keeps settings of an account or environment
+type Profile struct {
+	// AccessToken is the access token itself
+	AccessToken string `yaml:"access-token,omitempty"`
+	// TokenFile is path of a file containing the access token
+	TokenFile string `yaml:"token-file,omitempty"`
+	// TokenCommand is a shell command printing the access token in JSON. See auth.ExecProvider
+	TokenCommand string `yaml:"token-command,omitempty"`
+	ApiServer    string `yaml:"api-server,omitempty"`
+	Org          string `yaml:"org,omitempty"`
+	Cluster      string `yaml:"cluster,omitempty"`
+}
+
+// Config is content of the starctl config file
+type Config struct {
+	CurrentProfile string              `yaml:"current-profile,omitempty"`
+	Profiles       map[string]*Profile `yaml:"profiles,omitempty"`
+	CurrentContext string              `yaml:"current-context,omitempty"`
+	Contexts       map[string]*Context `yaml:"contexts,omitempty"`
+}
+
+// profileKeys are keys accepted by Profile.Set() in the order of display
+var profileKeys = []string{"access-token", "token-file", "token-command", "api-server", "org", "cluster"}
+
+// Path returns path of the config file
+func Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// Load reads the config file. Empty config is returned when the file does not exist.
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	return LoadFile(path)
+}
+
+func LoadFile(path string) (*Config, error) {
+	c := &Config{}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, fmt.Errorf("Invalid config file %s: %v", path, err)
+	}
+	return c, nil
+}
+
+// Save writes the config file. The file is readable only by the owner since it may contain access tokens.
+func (c *Config) Save() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	return c.SaveFile(path)
+}
+
+func (c *Config) SaveFile(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of an existing file, and the config may have access tokens
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0600)
+}
+
+// ProfileName returns name of the profile to use. name overrides the current profile when not empty.
+func (c *Config) ProfileName(name string) string {
+	if name != "" {
+		return name
+	}
+	if c.CurrentProfile != "" {
+		return c.CurrentProfile
+	}
+	return DefaultProfileName
+}
+
+// Profile returns the profile, or nil if not exists
+func (c *Config) Profile(name string) *Profile {
+	if c.Profiles == nil {
+		return nil
+	}
+	return c.Profiles[name]
+}
+
+// GetOrCreateProfile returns the profile, creating an empty one if not exists
+func (c *Config) GetOrCreateProfile(name string) *Profile {
+	if c.Profiles == nil {
+		c.Profiles = make(map[string]*Profile)
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		p = &Profile{}
+		c.Profiles[name] = p
+	}
+	return p
+}
+
+// ProfileNames returns sorted names of all profiles
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProfileKeys returns keys accepted by Profile.Set()
+func ProfileKeys() []string {
+	return append([]string{}, profileKeys...)
+}
+
+func (p *Profile) field(key string) (*string, error) {
+	switch key {
+	case "access-token":
+		return &p.AccessToken, nil
+	case "token-file":
+		return &p.TokenFile, nil
+	case "token-command":
+		return &p.TokenCommand, nil
+	case "api-server":
+		return &p.ApiServer, nil
+	case "org":
+		return &p.Org, nil
+	case "cluster":
+		return &p.Cluster, nil
+	}
+	return nil, fmt.Errorf("Unknown key '%s'. Available keys are %s", key, strings.Join(profileKeys, ", "))
+}
+
+// Get returns value of key
+func (p *Profile) Get(key string) (string, error) {
+	f, err := p.field(key)
+	if err != nil {
+		return "", err
+	}
+	return *f, nil
+}
+
+// Set sets value of key. Empty value unsets the key.
+func (p *Profile) Set(key string, value string) error {
+	f, err := p.field(key)
+	if err != nil {
+		return err
+	}
+	*f = value
+	return nil
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSaveAndLoad(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "starctl-config")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+
+	c, err := LoadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultProfileName, c.ProfileName(""))
+	assert.Nil(t, c.Profile(DefaultProfileName))
+
+	c.CurrentProfile = "work"
+	assert.Nil(t, c.GetOrCreateProfile("work").Set("org", "GITHUB/staroids"))
+	assert.NotNil(t, c.GetOrCreateProfile("work").Set("unknown", "value"))
+	assert.Nil(t, c.SaveFile(path))
+
+	info, _ := os.Stat(path)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// an existing file readable by others is fixed
+	assert.Nil(t, os.Chmod(path, 0644))
+	assert.Nil(t, c.SaveFile(path))
+	info, _ = os.Stat(path)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	loaded, err := LoadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "work", loaded.ProfileName(""))
+	assert.Equal(t, "other", loaded.ProfileName("other"))
+	assert.Equal(t, "GITHUB/staroids", loaded.Profile("work").Org)
+	assert.Equal(t, []string{"work"}, loaded.ProfileNames())
+}
+
+func TestLoadInvalidConfig(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "starctl-config")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+
+	ioutil.WriteFile(path, []byte("profiles:\n  default:\n    organization: GITHUB/staroids\n"), 0600)
+	_, err := LoadFile(path)
+	assert.NotNil(t, err)
+}
+
+func TestContexts(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "starctl-config")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+
+	c := &Config{}
+	assert.Equal(t, "", c.ContextName(""))
+	assert.NotNil(t, c.UseContext("dev"))
+
+	c.GetOrCreateContext("dev").Cluster = "default"
+	c.GetOrCreateContext("dev").Namespace = "my-ns"
+	c.GetOrCreateContext("prod").Org = "GITHUB/staroids"
+	assert.Nil(t, c.UseContext("dev"))
+	assert.Nil(t, c.SaveFile(path))
+
+	loaded, err := LoadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "dev", loaded.ContextName(""))
+	assert.Equal(t, "prod", loaded.ContextName("prod"))
+	assert.Equal(t, Context{Cluster: "default", Namespace: "my-ns"}, *loaded.Context("dev"))
+	assert.Equal(t, []string{"dev", "prod"}, loaded.ContextNames())
+}
+
+func TestFindProjectFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "starctl-project")
+	defer os.RemoveAll(dir)
+	sub := filepath.Join(dir, "a", "b")
+	assert.Nil(t, os.MkdirAll(sub, 0700))
+
+	path, err := FindProjectFile(sub)
+	assert.Nil(t, err)
+	assert.Equal(t, "", path)
+
+	projectFile := filepath.Join(dir, "a", ProjectFileName)
+	ioutil.WriteFile(projectFile, []byte("org: GITHUB/staroids\ntunnel-remotes:\n- 8080:web:80\n"), 0644)
+	path, err = FindProjectFile(sub)
+	assert.Nil(t, err)
+	assert.Equal(t, projectFile, path)
+
+	p, err := LoadProjectFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "GITHUB/staroids", p.Org)
+	assert.Equal(t, []string{"8080:web:80"}, p.TunnelRemotes)
+
+	ioutil.WriteFile(projectFile, []byte("organization: GITHUB/staroids\n"), 0644)
+	_, err = LoadProjectFile(path)
+	assert.NotNil(t, err)
+}
+
+func TestLoadNamespaceSpecs(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "starctl-spec")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "spec.yaml")
+
+	load := func(content string) ([]NamespaceSpec, error) {
+		ioutil.WriteFile(path, []byte(content), 0644)
+		return LoadNamespaceSpecs(path, "GITHUB/staroids", "default")
+	}
+
+	specs, err := load(`
+alias: dev
+project:
+  provider: GITHUB
+  owner: staroids
+  repo: app
+  branch: master
+---
+alias: pr-1
+org: GITHUB/other
+cluster: c1
+project: {provider: GITHUB, owner: staroids, repo: app, branch: pr-1, commit: abc123}
+state: paused
+`)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(specs))
+	assert.Equal(t, "GITHUB/staroids", specs[0].Org)
+	assert.Equal(t, "default", specs[0].Cluster)
+	assert.Equal(t, NamespaceStateRunning, specs[0].State)
+	assert.Equal(t, "abc123", specs[1].Project.Commit)
+	assert.Equal(t, NamespaceStatePaused, specs[1].State)
+
+	// json
+	specs, err = load(`{"alias": "dev", "project": {"provider": "GITHUB", "owner": "staroids", "repo": "app", "branch": "master"}}`)
+	assert.Nil(t, err)
+	assert.Equal(t, "app", specs[0].Project.Repo)
+
+	// all invalid fields are reported
+	_, err = load(`
+alias: "pr-*"
+org: staroids
+project: {provider: GITHUB, owner: staroids}
+state: stopped
+`)
+	assert.EqualError(t, err, path+": alias: 'pr-*' must not contain whitespace, '/' or any of '*?['\n"+
+		path+": org: 'staroids' must be in PROVIDER/name format (e.g. GITHUB/staroids)\n"+
+		path+": project.repo: is required\n"+
+		path+": project.branch: is required\n"+
+		path+": state: 'stopped' must be one of running, paused")
+
+	_, err = load("alias: dev\nproject: {provider: GITHUB, owner: staroids, repo: app, branch: master}\n---\nalias: dev\nproject: {provider: GITHUB, owner: staroids, repo: app, branch: master}\n")
+	assert.EqualError(t, err, path+" (document 2): alias: 'dev' is already described in document 1")
+
+	_, err = load("alias: dev\nbranch: master\n")
+	assert.Contains(t, err.Error(), "line 2: field branch not found")
+	assert.NotContains(t, err.Error(), "NamespaceSpec")
+
+	_, err = load("")
+	assert.EqualError(t, err, "Invalid namespace spec "+path+": no namespace is described")
+}
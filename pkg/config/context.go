@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Context selects org, cluster and namespace alias used when flags are not given
+type Context struct {
+	Org       string `yaml:"org,omitempty"`
+	Cluster   string `yaml:"cluster,omitempty"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+// Context returns the context, or nil if not exists
+func (c *Config) Context(name string) *Context {
+	if c.Contexts == nil {
+		return nil
+	}
+	return c.Contexts[name]
+}
+
+// ContextName returns name of the context to use. name overrides the current context when not empty.
+// Empty string when no context is selected.
+func (c *Config) ContextName(name string) string {
+	if name != "" {
+		return name
+	}
+	return c.CurrentContext
+}
+
+// GetOrCreateContext returns the context, creating an empty one if not exists
+func (c *Config) GetOrCreateContext(name string) *Context {
+	if c.Contexts == nil {
+		c.Contexts = make(map[string]*Context)
+	}
+	ctx, ok := c.Contexts[name]
+	if !ok {
+		ctx = &Context{}
+		c.Contexts[name] = ctx
+	}
+	return ctx
+}
+
+// UseContext makes the context current
+func (c *Config) UseContext(name string) error {
+	if c.Context(name) == nil {
+		return fmt.Errorf("Context '%s' not found", name)
+	}
+	c.CurrentContext = name
+	return nil
+}
+
+// ContextNames returns sorted names of all contexts
+func (c *Config) ContextNames() []string {
+	names := make([]string, 0, len(c.Contexts))
+	for name := range c.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,21 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/staroids/starctl/pkg/constants"
+)
+
+// Dir returns starctl configuration directory.
+// STARCTL_CONFIG_DIR environment variable overrides the default '<user config dir>/starctl'
+func Dir() (string, error) {
+	if dir := os.Getenv(constants.EnvStarctlConfigDir); dir != "" {
+		return dir, nil
+	}
+	userDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(userDir, "starctl"), nil
+}
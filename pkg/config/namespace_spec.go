@@ -0,0 +1,174 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var goTypeName = regexp.MustCompile(` in type config\.\w+`)
+
+// Desired states of a namespace created from a spec
+const (
+	NamespaceStateRunning = "running"
+	NamespaceStatePaused  = "paused"
+)
+
+// NamespaceSpec describes a namespace to create. See LoadNamespaceSpecs
+type NamespaceSpec struct {
+	Alias string `yaml:"alias"`
+	// Org is in PROVIDER/name format (e.g. GITHUB/staroids)
+	Org     string      `yaml:"org,omitempty"`
+	Cluster string      `yaml:"cluster,omitempty"`
+	Project ProjectSpec `yaml:"project"`
+	// State is the state to leave the namespace in after creation. running when empty
+	State string `yaml:"state,omitempty"`
+}
+
+// ProjectSpec is the project commit a namespace is created from
+type ProjectSpec struct {
+	Provider string `yaml:"provider"`
+	Owner    string `yaml:"owner"`
+	Repo     string `yaml:"repo"`
+	Branch   string `yaml:"branch"`
+	// Commit is optional. The latest commit of Branch is used when empty
+	Commit string `yaml:"commit,omitempty"`
+}
+
+// SpecError is an invalid field of a namespace spec
+type SpecError struct {
+	// Document is 1-based index of the spec in the file
+	Document int
+	// Field is path of the field (e.g. project.branch)
+	Field   string
+	Message string
+}
+
+// SpecErrors are all invalid fields found in a namespace spec file
+type SpecErrors struct {
+	Path   string
+	Errors []SpecError
+	// multiDocument is true when the file has more than one spec
+	multiDocument bool
+}
+
+func (e *SpecErrors) Error() string {
+	lines := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		if e.multiDocument {
+			lines[i] = fmt.Sprintf("%s (document %d): %s: %s", e.Path, err.Document, err.Field, err.Message)
+		} else {
+			lines[i] = fmt.Sprintf("%s: %s: %s", e.Path, err.Field, err.Message)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// LoadNamespaceSpecs reads namespace specs from a YAML or JSON file. A YAML file can have
+// multiple specs separated by '---'. Org and cluster of a spec default to defaultOrg and defaultCluster.
+// When any spec is invalid, *SpecErrors describing all invalid fields is returned.
+func LoadNamespaceSpecs(path string, defaultOrg string, defaultCluster string) ([]NamespaceSpec, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	specs := make([]NamespaceSpec, 0)
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.SetStrict(true)
+	for {
+		spec := NamespaceSpec{}
+		err := decoder.Decode(&spec)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// go type names mean nothing to the user
+			message := goTypeName.ReplaceAllString(err.Error(), "")
+			return nil, fmt.Errorf("Invalid namespace spec %s (document %d): %s", path, len(specs)+1, message)
+		}
+		if spec == (NamespaceSpec{}) {
+			// empty document, e.g. after trailing '---'
+			continue
+		}
+		if spec.Org == "" {
+			spec.Org = defaultOrg
+		}
+		if spec.Cluster == "" {
+			spec.Cluster = defaultCluster
+		}
+		if spec.State == "" {
+			spec.State = NamespaceStateRunning
+		}
+		specs = append(specs, spec)
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("Invalid namespace spec %s: no namespace is described", path)
+	}
+
+	specErrors := &SpecErrors{Path: path, multiDocument: len(specs) > 1}
+	for i := range specs {
+		for _, err := range specs[i].Validate() {
+			err.Document = i + 1
+			specErrors.Errors = append(specErrors.Errors, err)
+		}
+		for j := 0; j < i; j++ {
+			if specs[j].Alias == specs[i].Alias && specs[j].Org == specs[i].Org && specs[j].Cluster == specs[i].Cluster {
+				specErrors.Errors = append(specErrors.Errors, SpecError{Document: i + 1, Field: "alias", Message: fmt.Sprintf("'%s' is already described in document %d", specs[i].Alias, j+1)})
+			}
+		}
+	}
+	if len(specErrors.Errors) > 0 {
+		return nil, specErrors
+	}
+	return specs, nil
+}
+
+// Validate returns all invalid fields of the spec
+func (s *NamespaceSpec) Validate() []SpecError {
+	errs := make([]SpecError, 0)
+	invalid := func(field string, format string, args ...interface{}) {
+		errs = append(errs, SpecError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.Alias == "" {
+		invalid("alias", "is required")
+	} else if strings.ContainsAny(s.Alias, "*?[/ \t\n") {
+		invalid("alias", "'%s' must not contain whitespace, '/' or any of '*?['", s.Alias)
+	}
+
+	if s.Org == "" {
+		invalid("org", "is required")
+	} else if parts := strings.Split(s.Org, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		invalid("org", "'%s' must be in PROVIDER/name format (e.g. GITHUB/staroids)", s.Org)
+	}
+
+	if s.Cluster == "" {
+		invalid("cluster", "is required")
+	}
+
+	required := []struct {
+		field string
+		value string
+	}{
+		{"project.provider", s.Project.Provider},
+		{"project.owner", s.Project.Owner},
+		{"project.repo", s.Project.Repo},
+		{"project.branch", s.Project.Branch},
+	}
+	for _, r := range required {
+		if r.value == "" {
+			invalid(r.field, "is required")
+		}
+	}
+
+	if s.State != NamespaceStateRunning && s.State != NamespaceStatePaused {
+		invalid("state", "'%s' must be one of %s, %s", s.State, NamespaceStateRunning, NamespaceStatePaused)
+	}
+	return errs
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ProjectFileName is name of the project-local config file
+const ProjectFileName = ".starctl.yaml"
+
+// Project is content of a project-local .starctl.yaml.
+// It provides defaults for the repository it is placed in.
+type Project struct {
+	Org     string `yaml:"org,omitempty"`
+	Cluster string `yaml:"cluster,omitempty"`
+	// Project is commit location of the namespace to create (e.g. GITHUB/staroid/app:master)
+	Project string `yaml:"project,omitempty"`
+	NsAlias string `yaml:"ns-alias,omitempty"`
+	// TunnelRemotes are used by 'starctl tunnel' when no remote is given
+	TunnelRemotes []string `yaml:"tunnel-remotes,omitempty"`
+
+	// Path is where the project file is loaded from
+	Path string `yaml:"-"`
+}
+
+// FindProjectFile returns path of the nearest .starctl.yaml in dir or its parents.
+// Empty string when not found
+func FindProjectFile(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, ProjectFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// LoadProject reads the nearest project file from the working directory.
+// nil is returned when there's none.
+func LoadProject() (*Project, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	path, err := FindProjectFile(wd)
+	if err != nil || path == "" {
+		return nil, err
+	}
+	return LoadProjectFile(path)
+}
+
+func LoadProjectFile(path string) (*Project, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Project{}
+	if err := yaml.UnmarshalStrict(data, p); err != nil {
+		return nil, fmt.Errorf("Invalid project file %s: %v", path, err)
+	}
+	p.Path = path
+	return p, nil
+}
@@ -1,16 +1,34 @@
 package constants
 
 const (
-	Version               = "v0.0.1"
-	ApiServer             = "https://staroid.com/api"
-	EnvStaroidAccessToken = "STAROID_ACCESS_TOKEN"
-	EnvStaroidApiServer   = "STAROID_API_SERVER"
-	TunnelServicePort     = 57682
-	KubeproxyPort         = 57683
+	Version                        = "v0.0.1"
+	ApiServer                      = "https://staroid.com/api"
+	AuthURL                        = "https://staroid.com/cli/login"
+	AccessTokensURL                = "https://staroid.com/settings/accesstokens"
+	EnvStaroidAccessToken          = "STAROID_ACCESS_TOKEN"
+	EnvStaroidAccessTokenFile      = "STAROID_ACCESS_TOKEN_FILE"
+	EnvStaroidApiServer            = "STAROID_API_SERVER"
+	EnvStaroidMaxAttempts          = "STAROID_MAX_ATTEMPTS"
+	EnvStaroidRequestTimeout       = "STAROID_REQUEST_TIMEOUT"
+	EnvStaroidCACert               = "STAROID_CA_CERT"
+	EnvStaroidClientCert           = "STAROID_CLIENT_CERT"
+	EnvStaroidClientKey            = "STAROID_CLIENT_KEY"
+	EnvStarctlConfigDir            = "STARCTL_CONFIG_DIR"
+	EnvStaroidProfile              = "STAROID_PROFILE"
+	EnvStarctlContext              = "STARCTL_CONTEXT"
+	EnvStaroidOrg                  = "STAROID_ORG"
+	EnvStaroidCluster              = "STAROID_CLUSTER"
+	EnvStaroidProject              = "STAROID_PROJECT"
+	EnvStaroidNsAlias              = "STAROID_NS_ALIAS"
+	EnvStaroidAuthURL              = "STAROID_AUTH_URL"
+	EnvStarctlCredentialPassphrase = "STARCTL_CREDENTIAL_PASSPHRASE"
+	TunnelServicePort              = 57682
+	KubeproxyPort                  = 57683
 
 	K8S_LABEL_KEY_RESOURCE_SYSTEM         = "resource.staroid.com/system"
 	K8S_LABEL_VALUE_RESOURCE_SYSTEM_SHELL = "shell"
 
 	StatusPollingIntervalSec = 5
 	NsStartTimeoutSec        = 10 * 60
+	LookupCacheTTLSec        = 10 * 60
 )